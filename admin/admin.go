@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/cache"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/pagecache"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// Handler serves the admin API, which inspects and purges the service's caches and shows its config. It is served on
+// its own listener so that it is never reachable through the public routes.
+type Handler struct {
+	cfg       *config.Config
+	codeLists *cache.CodeListClient
+	pages     *pagecache.Cache
+}
+
+// CacheStats is the response to a request for the stats of the caches
+type CacheStats struct {
+	CodeListCache map[string]cache.Stats `json:"code_list_cache"`
+	PageCache     pagecache.Stats        `json:"page_cache"`
+}
+
+// CacheKeys is the response to a request for the keys held in the caches
+type CacheKeys struct {
+	CodeListCache map[string][]string `json:"code_list_cache"`
+	PageCache     []string            `json:"page_cache"`
+}
+
+// Purged is the response to a request to purge the caches, with the number of entries removed from each
+type Purged struct {
+	CodeListCache int `json:"code_list_cache"`
+	PageCache     int `json:"page_cache"`
+}
+
+// New creates a Handler for the provided config and caches
+func New(cfg *config.Config, codeLists *cache.CodeListClient, pages *pagecache.Cache) *Handler {
+	return &Handler{
+		cfg:       cfg,
+		codeLists: codeLists,
+		pages:     pages,
+	}
+}
+
+// Router returns the routes of the admin API. Every route requires the admin secret.
+func (h *Handler) Router() http.Handler {
+	router := mux.NewRouter()
+	router.StrictSlash(true).Path("/config").Methods("GET").HandlerFunc(h.Config)
+	router.StrictSlash(true).Path("/caches").Methods("GET").HandlerFunc(h.Stats)
+	router.StrictSlash(true).Path("/caches").Methods("DELETE").HandlerFunc(h.PurgeAll)
+	router.StrictSlash(true).Path("/caches/keys").Methods("GET").HandlerFunc(h.Keys)
+	router.StrictSlash(true).Path("/caches/pages").Methods("DELETE").HandlerFunc(h.pages.PurgeHandler)
+	router.StrictSlash(true).Path("/caches/code-lists/{codeListID}").Methods("DELETE").HandlerFunc(h.PurgeCodeList)
+	router.StrictSlash(true).Path("/caches/code-lists/{codeListID}/editions/{edition}").Methods("DELETE").HandlerFunc(h.PurgeEdition)
+
+	return requireAdminSecret(h.cfg.AdminSecret, router.ServeHTTP)
+}
+
+// Config writes the service's config, without the admin secret or anything else that is excluded from its JSON
+func (h *Handler) Config(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, req, h.cfg)
+}
+
+// Stats writes the stats of each of the code list caches and of the page cache
+func (h *Handler) Stats(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, req, CacheStats{
+		CodeListCache: h.codeLists.Stats(),
+		PageCache:     h.pages.Stats(),
+	})
+}
+
+// Keys writes the keys held in each of the code list caches and in the page cache
+func (h *Handler) Keys(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, req, CacheKeys{
+		CodeListCache: h.codeLists.Keys(),
+		PageCache:     h.pages.Keys(),
+	})
+}
+
+// PurgeAll removes every entry from the code list caches and the page cache
+func (h *Handler) PurgeAll(w http.ResponseWriter, req *http.Request) {
+	purged := Purged{
+		CodeListCache: h.codeLists.Purge(func(string) bool { return true }),
+		PageCache:     h.pages.Purge(""),
+	}
+	writePurged(w, req, purged, log.Data{})
+}
+
+// PurgeCodeList removes every entry for a code list, including the list of geography code lists that it is named
+// in, and every page that shows it
+func (h *Handler) PurgeCodeList(w http.ResponseWriter, req *http.Request) {
+	codeListID := mux.Vars(req)["codeListID"]
+	purged := Purged{
+		CodeListCache: h.codeLists.PurgeCodeList(codeListID),
+		PageCache:     h.pages.PurgeCodeList(codeListID),
+	}
+	writePurged(w, req, purged, log.Data{"code_list_id": codeListID})
+}
+
+// PurgeEdition removes every entry for an edition of a code list, including the list of the code list's editions,
+// and every page that shows the code list. Pages are purged for every edition, as a page for the latest edition does
+// not name it.
+func (h *Handler) PurgeEdition(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	codeListID, edition := vars["codeListID"], vars["edition"]
+	purged := Purged{
+		CodeListCache: h.codeLists.PurgeEdition(codeListID, edition),
+		PageCache:     h.pages.PurgeCodeList(codeListID),
+	}
+	writePurged(w, req, purged, log.Data{"code_list_id": codeListID, "edition": edition})
+}
+
+func writePurged(w http.ResponseWriter, req *http.Request, purged Purged, logData log.Data) {
+	logData["code_list_cache"] = purged.CodeListCache
+	logData["page_cache"] = purged.PageCache
+	log.Info(req.Context(), "purged caches", logData)
+
+	writeJSON(w, req, purged)
+}
+
+func writeJSON(w http.ResponseWriter, req *http.Request, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error(req.Context(), "error writing admin response", err)
+	}
+}
@@ -0,0 +1,143 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/cache"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-geography-controller/pagecache"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAdmin(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an admin API in front of populated code list and page caches", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		cfg.AdminSecret = "s3cr3t"
+
+		mockClient := &handlers.CodeListClientMock{
+			GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+				return codelist.CodeListResults{}, nil
+			},
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				return codelist.EditionsListResults{}, nil
+			},
+			GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				return codelist.CodesResults{}, nil
+			},
+		}
+		codeLists := cache.NewCodeListClient(mockClient, cfg)
+		codeLists.GetGeographyCodeLists(ctx, "", "")
+		codeLists.GetGeographyCodeLists(ctx, "", "")
+		for _, codeListID := range []string{"local-authority", "countries"} {
+			codeLists.GetCodeListEditions(ctx, "", "", codeListID)
+			codeLists.GetCodes(ctx, "", "", codeListID, "2019")
+			codeLists.GetCodes(ctx, "", "", codeListID, "2018")
+		}
+
+		pages := pagecache.New(time.Minute, 1<<20)
+		page := pages.Middleware(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html></html>"))
+		})
+		for _, path := range []string{"/geography", "/geography/local-authority", "/geography/local-authority/E06000001", "/geography/countries"} {
+			page(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+
+		router := New(cfg, codeLists, pages).Router()
+		serve := func(method, target string, v interface{}) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, target, nil)
+			req.Header.Set("Authorization", "Bearer s3cr3t")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if v != nil {
+				So(json.Unmarshal(w.Body.Bytes(), v), ShouldBeNil)
+			}
+			return w
+		}
+
+		Convey("requests without the secret are rejected", func() {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("DELETE", "/caches", nil))
+			So(w.Code, ShouldEqual, http.StatusUnauthorized)
+			So(pages.Len(), ShouldEqual, 4)
+		})
+
+		Convey("the stats of every cache are returned", func() {
+			var stats CacheStats
+			w := serve("GET", "/caches", &stats)
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+			So(stats.CodeListCache, ShouldHaveLength, 5)
+			So(stats.CodeListCache["code_lists"].Entries, ShouldEqual, 1)
+			So(stats.CodeListCache["code_lists"].Hits, ShouldEqual, 1)
+			So(stats.CodeListCache["code_lists"].Misses, ShouldEqual, 1)
+			So(stats.CodeListCache["codes"].Entries, ShouldEqual, 4)
+			So(stats.PageCache.Pages, ShouldEqual, 4)
+			So(stats.PageCache.Misses, ShouldEqual, 4)
+		})
+
+		Convey("the keys held in every cache are returned", func() {
+			var keys CacheKeys
+			serve("GET", "/caches/keys", &keys)
+			So(keys.CodeListCache["code_lists"], ShouldResemble, []string{"/code-lists?type=geography"})
+			So(keys.CodeListCache["editions"], ShouldResemble, []string{"/code-lists/countries/editions", "/code-lists/local-authority/editions"})
+			So(keys.PageCache, ShouldHaveLength, 4)
+			So(keys.PageCache[0], ShouldStartWith, "/geography/countries en ")
+		})
+
+		Convey("purging a code list removes its entries, the list of code lists and the pages that show it", func() {
+			var purged Purged
+			serve("DELETE", "/caches/code-lists/local-authority", &purged)
+			So(purged, ShouldResemble, Purged{CodeListCache: 4, PageCache: 3})
+
+			var keys CacheKeys
+			serve("GET", "/caches/keys", &keys)
+			So(keys.CodeListCache["code_lists"], ShouldBeEmpty)
+			So(keys.CodeListCache["editions"], ShouldResemble, []string{"/code-lists/countries/editions"})
+			So(keys.CodeListCache["codes"], ShouldHaveLength, 2)
+			So(keys.PageCache, ShouldHaveLength, 1)
+		})
+
+		Convey("purging an edition removes its entries, the list of editions and the pages that show the code list", func() {
+			var purged Purged
+			serve("DELETE", "/caches/code-lists/local-authority/editions/2018", &purged)
+			So(purged, ShouldResemble, Purged{CodeListCache: 2, PageCache: 3})
+
+			var keys CacheKeys
+			serve("GET", "/caches/keys", &keys)
+			So(keys.CodeListCache["code_lists"], ShouldHaveLength, 1)
+			So(keys.CodeListCache["codes"], ShouldHaveLength, 3)
+			So(keys.CodeListCache["codes"], ShouldContain, "/code-lists/local-authority/editions/2019/codes")
+		})
+
+		Convey("purging everything empties every cache", func() {
+			var purged Purged
+			serve("DELETE", "/caches", &purged)
+			So(purged, ShouldResemble, Purged{CodeListCache: 7, PageCache: 4})
+			So(pages.Len(), ShouldEqual, 0)
+		})
+
+		Convey("pages can be purged by path prefix", func() {
+			var purged map[string]int
+			serve("DELETE", "/caches/pages?path=/geography/local-authority", &purged)
+			So(purged["purged"], ShouldEqual, 2)
+		})
+
+		Convey("the config is returned without the admin secret", func() {
+			w := serve("GET", "/config", nil)
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldContainSubstring, `"BindAddr":":23700"`)
+			So(w.Body.String(), ShouldNotContainSubstring, "s3cr3t")
+		})
+	})
+}
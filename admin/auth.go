@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// requireAdminSecret wraps an admin handler so that it is only called for requests with the shared secret as a
+// bearer token. If no secret is configured the admin handler is disabled, and every request gets a 404.
+func requireAdminSecret(secret string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if secret == "" {
+			http.NotFound(w, req)
+			return
+		}
+
+		auth := req.Header.Get("Authorization")
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if token == auth || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		h(w, req)
+	}
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRequireAdminSecret(t *testing.T) {
+	Convey("Given an admin handler that requires a shared secret", t, func() {
+		called := false
+		admin := func(w http.ResponseWriter, req *http.Request) { called = true }
+		req := httptest.NewRequest("DELETE", "/admin/page-cache", nil)
+		w := httptest.NewRecorder()
+
+		Convey("requests with the secret as a bearer token reach the handler", func() {
+			req.Header.Set("Authorization", "Bearer s3cret")
+			requireAdminSecret("s3cret", admin)(w, req)
+			So(called, ShouldBeTrue)
+		})
+
+		Convey("requests without the secret are unauthorised", func() {
+			for _, auth := range []string{"", "s3cret", "Bearer wrong"} {
+				w = httptest.NewRecorder()
+				req.Header.Set("Authorization", auth)
+				requireAdminSecret("s3cret", admin)(w, req)
+				So(w.Code, ShouldEqual, http.StatusUnauthorized)
+			}
+			So(called, ShouldBeFalse)
+		})
+
+		Convey("the handler is disabled if no secret is configured", func() {
+			req.Header.Set("Authorization", "Bearer ")
+			requireAdminSecret("", admin)(w, req)
+			So(w.Code, ShouldEqual, http.StatusNotFound)
+			So(called, ShouldBeFalse)
+		})
+	})
+}
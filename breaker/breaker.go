@@ -0,0 +1,189 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+// The states of a circuit breaker. A closed breaker lets every call through, an open breaker fails every call
+// without making it, and a half-open breaker lets a single probe call through to decide whether to close again.
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// ErrOpen is returned instead of calling a dependency whose circuit breaker is open. It has the status code of an
+// unavailable service, so that it is reported to the user as one.
+type ErrOpen struct {
+	Name string
+}
+
+func (e ErrOpen) Error() string {
+	return fmt.Sprintf("circuit breaker for %s is open", e.Name)
+}
+
+// Code returns the status code that the error is reported with
+func (e ErrOpen) Code() int {
+	return http.StatusServiceUnavailable
+}
+
+// Breaker is a circuit breaker for a single downstream dependency. It opens once the dependency has failed threshold
+// times in a row, and fails calls fast while open. Once openTimeout has passed, a single probe call is let through,
+// which closes the breaker if it succeeds or opens it again if it fails.
+type Breaker struct {
+	name        string
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mutex    sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// New creates a closed Breaker for the named dependency. A threshold of zero or less disables the breaker, so that
+// it never opens.
+func New(name string, threshold int, openTimeout time.Duration) *Breaker {
+	return &Breaker{
+		name:        name,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		now:         time.Now,
+	}
+}
+
+// Name returns the name of the dependency the breaker protects
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.openTimeout)) {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Do calls fn if the breaker allows it, and records whether the dependency failed. If the breaker is open, fn is not
+// called and ErrOpen is returned.
+func (b *Breaker) Do(ctx context.Context, fn func() error) error {
+	allowed, probe := b.allow()
+	if !allowed {
+		return ErrOpen{Name: b.name}
+	}
+	err := fn()
+	b.record(ctx, probe, err)
+	return err
+}
+
+// Checker reports the state of the breaker: critical while it is open, a warning while it is probing the dependency,
+// and OK while it is closed
+func (b *Breaker) Checker(ctx context.Context, state *health.CheckState) error {
+	switch s := b.State(); s {
+	case StateOpen:
+		return state.Update(health.StatusCritical, fmt.Sprintf("circuit breaker for %s is open", b.name), 0)
+	case StateHalfOpen:
+		return state.Update(health.StatusWarning, fmt.Sprintf("circuit breaker for %s is half-open", b.name), 0)
+	default:
+		return state.Update(health.StatusOK, fmt.Sprintf("circuit breaker for %s is closed", b.name), 0)
+	}
+}
+
+// allow returns true if a call can be made, moving an open breaker to half-open once its timeout has passed, and
+// whether the call is the probe. Only one call at a time is let through while half-open.
+func (b *Breaker) allow() (allowed, probe bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.openTimeout)) {
+		b.state = StateHalfOpen
+	}
+
+	switch b.state {
+	case StateOpen:
+		return false, false
+	case StateHalfOpen:
+		if b.probing {
+			return false, false
+		}
+		b.probing = true
+		return true, true
+	default:
+		return true, false
+	}
+}
+
+// record updates the breaker with the outcome of a call. Calls cancelled by the caller say nothing about the
+// dependency, so they are not counted either way. Only the probe can change the state of a breaker that is not
+// closed, so calls that started before it opened are not counted once it has.
+func (b *Breaker) record(ctx context.Context, probe bool, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	if errors.Is(err, context.Canceled) || (!probe && b.state != StateClosed) {
+		return
+	}
+
+	if !isFailure(err) {
+		b.failures = 0
+		if probe {
+			b.state = StateClosed
+			log.Info(ctx, "circuit breaker closed", log.Data{"dependency": b.name})
+		}
+		return
+	}
+
+	b.failures++
+	if probe || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = StateOpen
+		b.openedAt = b.now()
+		log.Warn(ctx, "circuit breaker opened", log.Data{"dependency": b.name, "failures": b.failures, "open_timeout": b.openTimeout.String()}, log.FormatErrors([]error{err}))
+	}
+}
+
+// isFailure returns true if err means the dependency is unavailable: a transport error, a timeout, or a status of
+// 429 or 5xx. Any other status is a response to a bad request, which the dependency handled correctly.
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		code := coded.Code()
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testStatusError int
+
+func (e testStatusError) Error() string { return http.StatusText(int(e)) }
+func (e testStatusError) Code() int     { return int(e) }
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	errUnavailable := errors.New("connection refused")
+
+	Convey("Given a breaker that opens after 3 failures for 10 seconds", t, func() {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		b := New("code list API", 3, 10*time.Second)
+		b.now = func() time.Time { return now }
+
+		calls := 0
+		call := func(err error) error {
+			return b.Do(ctx, func() error {
+				calls++
+				return err
+			})
+		}
+		checkState := func() string {
+			state := health.NewCheckState("code list API circuit breaker")
+			So(b.Checker(ctx, state), ShouldBeNil)
+			return state.Status()
+		}
+
+		Convey("it stays closed until the dependency has failed 3 times in a row", func() {
+			call(errUnavailable)
+			call(errUnavailable)
+			call(nil)
+			call(errUnavailable)
+			call(errUnavailable)
+			So(b.State(), ShouldEqual, StateClosed)
+			So(checkState(), ShouldEqual, health.StatusOK)
+		})
+
+		Convey("responses to bad requests and cancelled calls are not failures", func() {
+			for i := 0; i < 3; i++ {
+				call(testStatusError(http.StatusNotFound))
+				call(context.Canceled)
+			}
+			So(b.State(), ShouldEqual, StateClosed)
+		})
+
+		Convey("once it has opened", func() {
+			call(testStatusError(http.StatusBadGateway))
+			call(context.DeadlineExceeded)
+			call(errUnavailable)
+			So(b.State(), ShouldEqual, StateOpen)
+			So(checkState(), ShouldEqual, health.StatusCritical)
+
+			Convey("calls fail fast without reaching the dependency", func() {
+				err := call(nil)
+				So(calls, ShouldEqual, 3)
+				So(err, ShouldResemble, ErrOpen{Name: "code list API"})
+				So(err.(ErrOpen).Code(), ShouldEqual, http.StatusServiceUnavailable)
+			})
+
+			Convey("a single probe is let through once the timeout has passed", func() {
+				now = now.Add(10 * time.Second)
+				So(b.State(), ShouldEqual, StateHalfOpen)
+				So(checkState(), ShouldEqual, health.StatusWarning)
+
+				var concurrent error
+				err := b.Do(ctx, func() error {
+					concurrent = call(nil)
+					return nil
+				})
+				So(err, ShouldBeNil)
+				So(concurrent, ShouldResemble, ErrOpen{Name: "code list API"})
+
+				Convey("which closes the breaker if it succeeds", func() {
+					So(b.State(), ShouldEqual, StateClosed)
+					So(call(nil), ShouldBeNil)
+					So(calls, ShouldEqual, 4)
+				})
+			})
+
+			Convey("a failed probe opens the breaker again for the full timeout", func() {
+				now = now.Add(10 * time.Second)
+				So(call(errUnavailable), ShouldEqual, errUnavailable)
+				So(b.State(), ShouldEqual, StateOpen)
+
+				now = now.Add(9 * time.Second)
+				So(call(nil), ShouldResemble, ErrOpen{Name: "code list API"})
+				So(calls, ShouldEqual, 4)
+			})
+		})
+	})
+
+	Convey("Given a breaker with a call that started before it opened", t, func() {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		b := New("code list API", 3, 10*time.Second)
+		b.now = func() time.Time { return now }
+		open := func() {
+			for i := 0; i < 3; i++ {
+				b.Do(ctx, func() error { return errUnavailable })
+			}
+			So(b.State(), ShouldEqual, StateOpen)
+		}
+
+		Convey("the call succeeding once it is half-open does not close it in place of the probe", func() {
+			err := b.Do(ctx, func() error {
+				open()
+				now = now.Add(10 * time.Second)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(b.State(), ShouldEqual, StateHalfOpen)
+
+			probed := false
+			So(b.Do(ctx, func() error {
+				probed = true
+				return nil
+			}), ShouldBeNil)
+			So(probed, ShouldBeTrue)
+			So(b.State(), ShouldEqual, StateClosed)
+		})
+
+		Convey("the call failing while the probe is in progress does not open it again", func() {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			done := make(chan error)
+			go func() {
+				done <- b.Do(ctx, func() error {
+					close(started)
+					<-release
+					return errUnavailable
+				})
+			}()
+			<-started
+			open()
+			now = now.Add(10 * time.Second)
+
+			err := b.Do(ctx, func() error {
+				close(release)
+				So(<-done, ShouldEqual, errUnavailable)
+				So(b.State(), ShouldEqual, StateHalfOpen)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(b.State(), ShouldEqual, StateClosed)
+		})
+	})
+
+	Convey("Given a breaker with a threshold of zero", t, func() {
+		b := New("dataset API", 0, 10*time.Second)
+
+		Convey("it never opens", func() {
+			for i := 0; i < 10; i++ {
+				b.Do(ctx, func() error { return errUnavailable })
+			}
+			So(b.State(), ShouldEqual, StateClosed)
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package breaker
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+// CodeListClient is a handlers.CodeListClient that calls the client it wraps through a circuit breaker
+type CodeListClient struct {
+	client  handlers.CodeListClient
+	breaker *Breaker
+}
+
+// NewCodeListClient wraps the provided client with the provided breaker
+func NewCodeListClient(client handlers.CodeListClient, breaker *Breaker) *CodeListClient {
+	return &CodeListClient{
+		client:  client,
+		breaker: breaker,
+	}
+}
+
+// GetGeographyCodeLists returns the geography code lists, unless the breaker is open
+func (c *CodeListClient) GetGeographyCodeLists(ctx context.Context, userAuthToken string, serviceAuthToken string) (results codelist.CodeListResults, err error) {
+	err = c.breaker.Do(ctx, func() (err error) {
+		results, err = c.client.GetGeographyCodeLists(ctx, userAuthToken, serviceAuthToken)
+		return err
+	})
+	return results, err
+}
+
+// GetCodeListEditions returns the editions of a code list, unless the breaker is open
+func (c *CodeListClient) GetCodeListEditions(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (editions codelist.EditionsListResults, err error) {
+	err = c.breaker.Do(ctx, func() (err error) {
+		editions, err = c.client.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
+		return err
+	})
+	return editions, err
+}
+
+// GetCodes returns the codes of an edition of a code list, unless the breaker is open
+func (c *CodeListClient) GetCodes(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codes codelist.CodesResults, err error) {
+	err = c.breaker.Do(ctx, func() (err error) {
+		codes, err = c.client.GetCodes(ctx, userAuthToken, serviceAuthToken, codeListID, edition)
+		return err
+	})
+	return codes, err
+}
+
+// GetCodeByID returns a single code of an edition of a code list, unless the breaker is open
+func (c *CodeListClient) GetCodeByID(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (code codelist.CodeResult, err error) {
+	err = c.breaker.Do(ctx, func() (err error) {
+		code, err = c.client.GetCodeByID(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+		return err
+	})
+	return code, err
+}
+
+// GetDatasetsByCode returns the datasets related to a code, unless the breaker is open
+func (c *CodeListClient) GetDatasetsByCode(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (datasets codelist.DatasetsResult, err error) {
+	err = c.breaker.Do(ctx, func() (err error) {
+		datasets, err = c.client.GetDatasetsByCode(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+		return err
+	})
+	return datasets, err
+}
@@ -0,0 +1,47 @@
+package breaker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCodeListClient(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a code list client behind a breaker that opens after 2 failures", t, func() {
+		mockClient := &handlers.CodeListClientMock{
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				return codelist.EditionsListResults{}, testStatusError(http.StatusInternalServerError)
+			},
+			GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				return codelist.CodesResults{Count: 1}, nil
+			},
+		}
+		cli := NewCodeListClient(mockClient, New("code list API", 2, time.Minute))
+
+		Convey("results are returned from the client while the breaker is closed", func() {
+			codes, err := cli.GetCodes(ctx, "", "", "local-authority", "2018")
+			So(err, ShouldBeNil)
+			So(codes.Count, ShouldEqual, 1)
+		})
+
+		Convey("failures of any method open the breaker for every method, which then fail fast", func() {
+			cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			cli.GetCodeListEditions(ctx, "", "", "local-authority")
+
+			_, err := cli.GetCodes(ctx, "", "", "local-authority", "2018")
+			So(err, ShouldResemble, ErrOpen{Name: "code list API"})
+			So(mockClient.GetCodesCalls(), ShouldHaveLength, 0)
+
+			cliErr, ok := err.(handlers.ClientError)
+			So(ok, ShouldBeTrue)
+			So(cliErr.Code(), ShouldEqual, http.StatusServiceUnavailable)
+		})
+	})
+}
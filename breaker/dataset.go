@@ -0,0 +1,40 @@
+package breaker
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-api-clients-go/dataset"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+// DatasetClient is a handlers.DatasetClient that calls the client it wraps through a circuit breaker
+type DatasetClient struct {
+	client  handlers.DatasetClient
+	breaker *Breaker
+}
+
+// NewDatasetClient wraps the provided client with the provided breaker
+func NewDatasetClient(client handlers.DatasetClient, breaker *Breaker) *DatasetClient {
+	return &DatasetClient{
+		client:  client,
+		breaker: breaker,
+	}
+}
+
+// Get returns the details of a dataset, unless the breaker is open
+func (c *DatasetClient) Get(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID string) (details dataset.DatasetDetails, err error) {
+	err = c.breaker.Do(ctx, func() (err error) {
+		details, err = c.client.Get(ctx, userAuthToken, serviceAuthToken, collectionID, datasetID)
+		return err
+	})
+	return details, err
+}
+
+// GetVersion returns a version of an edition of a dataset, unless the breaker is open
+func (c *DatasetClient) GetVersion(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (m dataset.Version, err error) {
+	err = c.breaker.Do(ctx, func() (err error) {
+		m, err = c.client.GetVersion(ctx, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version)
+		return err
+	})
+	return m, err
+}
@@ -0,0 +1,31 @@
+package breaker
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+// RenderClient is a handlers.RenderClient that calls the client it wraps through a circuit breaker
+type RenderClient struct {
+	client  handlers.RenderClient
+	breaker *Breaker
+}
+
+// NewRenderClient wraps the provided client with the provided breaker
+func NewRenderClient(client handlers.RenderClient, breaker *Breaker) *RenderClient {
+	return &RenderClient{
+		client:  client,
+		breaker: breaker,
+	}
+}
+
+// Do renders a template with the provided page model, unless the breaker is open. The renderer client does not take
+// a context, so breaker state changes are logged without one.
+func (c *RenderClient) Do(templateName string, page []byte) (html []byte, err error) {
+	err = c.breaker.Do(context.Background(), func() (err error) {
+		html, err = c.client.Do(templateName, page)
+		return err
+	})
+	return html, err
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is an in-memory key/value store where entries expire after a fixed TTL. Expired entries are kept for a
+// further grace period, during which they can still be looked up as stale. Once the store holds maxSize entries,
+// the least recently used entry is evicted to make room for a new one.
+type Cache struct {
+	ttl     time.Duration
+	grace   time.Duration
+	maxSize int
+	mutex   sync.Mutex
+	items   map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+	hits    int
+	stale   int
+	misses  int
+}
+
+// Stats describes the configuration and use of a Cache
+type Stats struct {
+	TTL     string `json:"ttl"`
+	Grace   string `json:"grace"`
+	MaxSize int    `json:"max_size"`
+	Entries int    `json:"entries"`
+	Hits    int    `json:"hits"`
+	Stale   int    `json:"stale"`
+	Misses  int    `json:"misses"`
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// New creates a Cache with the provided TTL and maximum number of entries. A TTL or maxSize
+// of zero or less disables the cache, so that every Get is a miss.
+func New(ttl time.Duration, maxSize int) *Cache {
+	return NewWithGrace(ttl, maxSize, 0)
+}
+
+// NewWithGrace creates a Cache like New, which keeps entries for the grace period after they expire so that they
+// can still be looked up as stale
+func NewWithGrace(ttl time.Duration, maxSize int, grace time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		grace:   grace,
+		maxSize: maxSize,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Enabled returns true if the cache is able to hold any entries
+func (c *Cache) Enabled() bool {
+	return c.ttl > 0 && c.maxSize > 0
+}
+
+// Get returns the value stored against key, if it exists and has not expired
+func (c *Cache) Get(key string) (interface{}, bool) {
+	value, expiredFor, ok := c.Lookup(key)
+	if !ok || expiredFor >= 0 {
+		return nil, false
+	}
+	return value, true
+}
+
+// Lookup returns the value stored against key, if it exists, along with how long ago it expired. The duration is
+// negative while the value is fresh, and never reaches the grace period, as entries are removed once it has passed.
+func (c *Cache) Lookup(key string) (interface{}, time.Duration, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil, 0, false
+	}
+
+	e := elem.Value.(*entry)
+	expiredFor := c.now().Sub(e.expires)
+	if expiredFor >= c.grace && expiredFor >= 0 {
+		c.removeElement(elem)
+		c.misses++
+		return nil, 0, false
+	}
+
+	if expiredFor < 0 {
+		c.hits++
+	} else {
+		c.stale++
+	}
+	c.order.MoveToFront(elem)
+	return e.value, expiredFor, true
+}
+
+// Set stores value against key, replacing any existing value and resetting its expiry
+func (c *Cache) Set(key string, value interface{}) {
+	if !c.Enabled() {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry)
+		e.value = value
+		e.expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.maxSize {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Delete removes the entry stored against key, if any
+func (c *Cache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Purge removes every entry from the cache
+func (c *Cache) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// PurgeMatching removes every entry whose key and value match, and returns the number of entries removed
+func (c *Cache) PurgeMatching(match func(key string, value interface{}) bool) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	purged := 0
+	for key, elem := range c.items {
+		if match(key, elem.Value.(*entry).value) {
+			c.removeElement(elem)
+			purged++
+		}
+	}
+	return purged
+}
+
+// Keys returns the keys of the entries currently held, from the most to the least recently used
+func (c *Cache) Keys() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		keys = append(keys, elem.Value.(*entry).key)
+	}
+	return keys
+}
+
+// Stats returns the configuration of the cache, the number of entries it holds, and the number of lookups that
+// found a fresh entry, found a stale entry, or missed since it was created
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return Stats{
+		TTL:     c.ttl.String(),
+		Grace:   c.grace.String(),
+		MaxSize: c.maxSize,
+		Entries: c.order.Len(),
+		Hits:    c.hits,
+		Stale:   c.stale,
+		Misses:  c.misses,
+	}
+}
+
+// Len returns the number of entries currently held, including any that have expired but not yet been removed
+func (c *Cache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*entry).key)
+}
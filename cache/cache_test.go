@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCache(t *testing.T) {
+
+	Convey("Given a cache with a TTL of one minute and a maximum size of 2", t, func() {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		c := New(time.Minute, 2)
+		c.now = func() time.Time { return now }
+
+		Convey("a stored value is returned before it expires", func() {
+			c.Set("a", 1)
+			v, ok := c.Get("a")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, 1)
+		})
+
+		Convey("a stored value is not returned once it has expired", func() {
+			c.Set("a", 1)
+			now = now.Add(time.Minute)
+			_, ok := c.Get("a")
+			So(ok, ShouldBeFalse)
+			So(c.Len(), ShouldEqual, 0)
+		})
+
+		Convey("the least recently used entry is evicted when the cache is full", func() {
+			c.Set("a", 1)
+			c.Set("b", 2)
+			c.Get("a")
+			c.Set("c", 3)
+
+			_, ok := c.Get("b")
+			So(ok, ShouldBeFalse)
+			_, ok = c.Get("a")
+			So(ok, ShouldBeTrue)
+			_, ok = c.Get("c")
+			So(ok, ShouldBeTrue)
+			So(c.Len(), ShouldEqual, 2)
+		})
+
+		Convey("deleted and purged entries are no longer returned", func() {
+			c.Set("a", 1)
+			c.Set("b", 2)
+			c.Delete("a")
+			_, ok := c.Get("a")
+			So(ok, ShouldBeFalse)
+
+			c.Purge()
+			_, ok = c.Get("b")
+			So(ok, ShouldBeFalse)
+			So(c.Len(), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given a cache with a maximum size of 0", t, func() {
+		c := New(time.Minute, 0)
+
+		Convey("values are never stored", func() {
+			c.Set("a", 1)
+			_, ok := c.Get("a")
+			So(ok, ShouldBeFalse)
+			So(c.Enabled(), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given a cache with a TTL of one minute and a grace period of one hour", t, func() {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		c := NewWithGrace(time.Minute, 2, time.Hour)
+		c.now = func() time.Time { return now }
+		c.Set("a", 1)
+
+		Convey("a fresh value is looked up with a negative expiry", func() {
+			v, expiredFor, ok := c.Lookup("a")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, 1)
+			So(expiredFor, ShouldBeLessThan, 0)
+		})
+
+		Convey("an expired value can be looked up as stale during the grace period, but not got", func() {
+			now = now.Add(31 * time.Minute)
+			v, expiredFor, ok := c.Lookup("a")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, 1)
+			So(expiredFor, ShouldEqual, 30*time.Minute)
+
+			_, ok = c.Get("a")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("an expired value is removed once the grace period has passed", func() {
+			now = now.Add(time.Hour + time.Minute)
+			_, _, ok := c.Lookup("a")
+			So(ok, ShouldBeFalse)
+			So(c.Len(), ShouldEqual, 0)
+		})
+
+		Convey("lookups are counted as hits, stale hits and misses", func() {
+			c.Lookup("a")
+			now = now.Add(31 * time.Minute)
+			c.Lookup("a")
+			c.Lookup("b")
+
+			stats := c.Stats()
+			So(stats.Entries, ShouldEqual, 1)
+			So(stats.Hits, ShouldEqual, 1)
+			So(stats.Stale, ShouldEqual, 1)
+			So(stats.Misses, ShouldEqual, 1)
+		})
+
+		Convey("only matching entries are purged", func() {
+			c.Set("b", 2)
+			So(c.Keys(), ShouldResemble, []string{"b", "a"})
+			So(c.PurgeMatching(func(key string, value interface{}) bool { return key == "a" }), ShouldEqual, 1)
+			So(c.Keys(), ShouldResemble, []string{"b"})
+		})
+	})
+}
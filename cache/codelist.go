@@ -0,0 +1,343 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/v2/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// refreshTimeout bounds the background requests that refresh stale entries
+const refreshTimeout = 30 * time.Second
+
+// Cache keys, which are the code list API paths that were requested
+const (
+	codeListsKey = "/code-lists?type=geography"
+	editionsKey  = "/code-lists/%s/editions"
+	codesKey     = "/code-lists/%s/editions/%s/codes"
+	codeKey      = "/code-lists/%s/editions/%s/codes/%s"
+	datasetsKey  = "/code-lists/%s/editions/%s/codes/%s/datasets"
+)
+
+// CodeListClient is a handlers.CodeListClient that caches successful responses from the client it wraps.
+// Each method has its own cache so that TTLs and sizes can be tuned to the shape of the data returned.
+//
+// Entries that have expired less than the stale-while-revalidate period ago are still served while a single
+// background request refreshes them. If a request for an expired entry fails, the entry is served instead of the
+// error for the stale-if-error period after it expired. Responses to requests that were made before a purge are not
+// stored, as they could be the data that the purge removed.
+type CodeListClient struct {
+	client               handlers.CodeListClient
+	codeLists            *Cache
+	editions             *Cache
+	codes                *Cache
+	code                 *Cache
+	datasets             *Cache
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+
+	mutex      sync.Mutex
+	refreshing map[string]bool
+	stale      map[string]time.Time
+	purges     uint64
+	now        func() time.Time
+}
+
+// NewCodeListClient wraps the provided client with caches sized according to the config
+func NewCodeListClient(client handlers.CodeListClient, cfg *config.Config) *CodeListClient {
+	grace := cfg.CacheStaleWhileRevalidate
+	if cfg.CacheStaleIfError > grace {
+		grace = cfg.CacheStaleIfError
+	}
+
+	return &CodeListClient{
+		client:               client,
+		codeLists:            NewWithGrace(cfg.CodeListsCacheTTL, cfg.CodeListsCacheMaxSize, grace),
+		editions:             NewWithGrace(cfg.EditionsCacheTTL, cfg.EditionsCacheMaxSize, grace),
+		codes:                NewWithGrace(cfg.CodesCacheTTL, cfg.CodesCacheMaxSize, grace),
+		code:                 NewWithGrace(cfg.CodeCacheTTL, cfg.CodeCacheMaxSize, grace),
+		datasets:             NewWithGrace(cfg.DatasetsByCodeCacheTTL, cfg.DatasetsByCodeCacheMaxSize, grace),
+		staleWhileRevalidate: cfg.CacheStaleWhileRevalidate,
+		staleIfError:         cfg.CacheStaleIfError,
+		refreshing:           make(map[string]bool),
+		stale:                make(map[string]time.Time),
+		now:                  time.Now,
+	}
+}
+
+// GetGeographyCodeLists returns the geography code lists, from the cache if possible
+func (c *CodeListClient) GetGeographyCodeLists(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+	key := codeListsKey
+	v, err := c.fetch(ctx, c.codeLists, userAuthToken, key, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetGeographyCodeLists(ctx, userAuthToken, serviceAuthToken)
+	})
+	results, _ := v.(codelist.CodeListResults)
+	return results, err
+}
+
+// GetCodeListEditions returns the editions of a code list, from the cache if possible
+func (c *CodeListClient) GetCodeListEditions(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+	key := fmt.Sprintf(editionsKey, codeListID)
+	v, err := c.fetch(ctx, c.editions, userAuthToken, key, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
+	})
+	editions, _ := v.(codelist.EditionsListResults)
+	return editions, err
+}
+
+// GetCodes returns the codes of an edition of a code list, from the cache if possible
+func (c *CodeListClient) GetCodes(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+	key := fmt.Sprintf(codesKey, codeListID, edition)
+	v, err := c.fetch(ctx, c.codes, userAuthToken, key, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetCodes(ctx, userAuthToken, serviceAuthToken, codeListID, edition)
+	})
+	codes, _ := v.(codelist.CodesResults)
+	return codes, err
+}
+
+// GetCodeByID returns a single code of an edition of a code list, from the cache if possible
+func (c *CodeListClient) GetCodeByID(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+	key := fmt.Sprintf(codeKey, codeListID, edition, codeID)
+	v, err := c.fetch(ctx, c.code, userAuthToken, key, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetCodeByID(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+	})
+	code, _ := v.(codelist.CodeResult)
+	return code, err
+}
+
+// GetDatasetsByCode returns the datasets related to a code, from the cache if possible
+func (c *CodeListClient) GetDatasetsByCode(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+	key := fmt.Sprintf(datasetsKey, codeListID, edition, codeID)
+	v, err := c.fetch(ctx, c.datasets, userAuthToken, key, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetDatasetsByCode(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+	})
+	datasets, _ := v.(codelist.DatasetsResult)
+	return datasets, err
+}
+
+// caches returns each of the client's caches by the name it is reported with
+func (c *CodeListClient) caches() map[string]*Cache {
+	return map[string]*Cache{
+		"code_lists": c.codeLists,
+		"editions":   c.editions,
+		"codes":      c.codes,
+		"code":       c.code,
+		"datasets":   c.datasets,
+	}
+}
+
+// Stats returns the stats of each of the client's caches
+func (c *CodeListClient) Stats() map[string]Stats {
+	stats := make(map[string]Stats)
+	for name, store := range c.caches() {
+		stats[name] = store.Stats()
+	}
+	return stats
+}
+
+// Keys returns the keys held in each of the client's caches. Keys are the code list API paths that were requested.
+func (c *CodeListClient) Keys() map[string][]string {
+	keys := make(map[string][]string)
+	for name, store := range c.caches() {
+		keys[name] = store.Keys()
+	}
+	return keys
+}
+
+// Purge removes every entry whose key matches from each of the client's caches, and returns the number of entries
+// removed
+func (c *CodeListClient) Purge(match func(key string) bool) int {
+	c.countPurge()
+	purged := 0
+	for _, store := range c.caches() {
+		purged += store.PurgeMatching(func(key string, _ interface{}) bool {
+			return match(key)
+		})
+	}
+	return purged
+}
+
+// PurgeCodeList removes every entry for a code list, including the list of geography code lists that names it, and
+// returns the number of entries removed
+func (c *CodeListClient) PurgeCodeList(codeListID string) int {
+	editions := fmt.Sprintf(editionsKey, codeListID)
+	return c.Purge(func(key string) bool {
+		return key == codeListsKey || key == editions || strings.HasPrefix(key, editions+"/")
+	})
+}
+
+// PurgeEdition removes every entry for an edition of a code list, including the code list's list of editions, and
+// returns the number of entries removed
+func (c *CodeListClient) PurgeEdition(codeListID, edition string) int {
+	editions := fmt.Sprintf(editionsKey, codeListID)
+	prefix := editions + "/" + edition + "/"
+	return c.Purge(func(key string) bool {
+		return key == editions || strings.HasPrefix(key, prefix)
+	})
+}
+
+// Code identifies a code of an edition of a code list
+type Code struct {
+	CodeListID string
+	Edition    string
+	ID         string
+}
+
+// PurgeDataset removes the datasets related to each code that a dataset is related to, and returns those codes
+func (c *CodeListClient) PurgeDataset(datasetID string) []Code {
+	c.countPurge()
+	var codes []Code
+	c.datasets.PurgeMatching(func(key string, value interface{}) bool {
+		datasets, _ := value.(codelist.DatasetsResult)
+		for _, dataset := range datasets.Datasets {
+			if dataset.Links.Self.ID == datasetID {
+				if parts := strings.Split(key, "/"); len(parts) == 8 {
+					codes = append(codes, Code{CodeListID: parts[2], Edition: parts[4], ID: parts[6]})
+				}
+				return true
+			}
+		}
+		return false
+	})
+	return codes
+}
+
+// countPurge records that a purge is starting, so that responses to requests made before it are not stored
+func (c *CodeListClient) countPurge() {
+	c.mutex.Lock()
+	c.purges++
+	c.mutex.Unlock()
+}
+
+// purgeCount returns the number of purges that have started, to be passed to setUnlessPurged once a request completes
+func (c *CodeListClient) purgeCount() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.purges
+}
+
+// setUnlessPurged stores v against key in store, unless a purge has started since purges was counted
+func (c *CodeListClient) setUnlessPurged(store *Cache, key string, v interface{}, purges uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.purges == purges {
+		store.Set(key, v)
+	}
+}
+
+// Checker reports a warning while stale data is being served because the code list API failed to refresh it
+func (c *CodeListClient) Checker(ctx context.Context, state *health.CheckState) error {
+	c.mutex.Lock()
+	now := c.now()
+	for key, until := range c.stale {
+		if !now.Before(until) {
+			delete(c.stale, key)
+		}
+	}
+	stale := len(c.stale)
+	c.mutex.Unlock()
+
+	if stale > 0 {
+		return state.Update(health.StatusWarning, fmt.Sprintf("serving stale data for %d cached code list API responses", stale), 0)
+	}
+	return state.Update(health.StatusOK, "cached code list API responses are up to date", 0)
+}
+
+// fetch returns the value stored against key in store if it is fresh, otherwise it requests it with fn. Preview
+// requests always use fn, and are never cached.
+func (c *CodeListClient) fetch(ctx context.Context, store *Cache, userAuthToken, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if isPreview(ctx, userAuthToken) {
+		return fn(ctx)
+	}
+
+	cached, expiredFor, ok := store.Lookup(key)
+	if ok && expiredFor < 0 {
+		return cached, nil
+	}
+	if ok && expiredFor < c.staleWhileRevalidate {
+		c.refresh(ctx, store, key, expiredFor, fn)
+		return cached, nil
+	}
+
+	purges := c.purgeCount()
+	v, err := fn(ctx)
+	if err != nil {
+		if ok && expiredFor < c.staleIfError {
+			log.Warn(ctx, "error requesting expired cache entry, serving stale value", log.Data{"key": key, "expired_for": expiredFor.String()}, log.FormatErrors([]error{err}))
+			c.setStale(key, c.staleIfError-expiredFor)
+			return cached, nil
+		}
+		c.setStale(key, 0)
+		return nil, err
+	}
+
+	c.setUnlessPurged(store, key, v, purges)
+	c.setStale(key, 0)
+	return v, nil
+}
+
+// refresh requests key with fn in the background and stores the result, unless a refresh of key is already running or
+// the cache is purged before it completes. The request is detached from ctx so that it can outlive the request that
+// triggered it, but stays in its trace.
+func (c *CodeListClient) refresh(ctx context.Context, store *Cache, key string, expiredFor time.Duration, fn func(ctx context.Context) (interface{}, error)) {
+	c.mutex.Lock()
+	if c.refreshing[key] {
+		c.mutex.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	purges := c.purges
+	c.mutex.Unlock()
+
+	go func() {
+		defer func() {
+			c.mutex.Lock()
+			delete(c.refreshing, key)
+			c.mutex.Unlock()
+		}()
+
+		refreshCtx, cancel := context.WithTimeout(trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx)), refreshTimeout)
+		defer cancel()
+
+		v, err := fn(refreshCtx)
+		if err != nil {
+			log.Warn(ctx, "error refreshing stale cache entry", log.Data{"key": key}, log.FormatErrors([]error{err}))
+			c.setStale(key, c.staleWhileRevalidate-expiredFor)
+			return
+		}
+		c.setUnlessPurged(store, key, v, purges)
+		c.setStale(key, 0)
+	}()
+}
+
+// setStale records that the value served for key is stale, because it could not be refreshed, for as long as the
+// stale value can still be served. A duration of zero or less records that key is no longer stale.
+func (c *CodeListClient) setStale(key string, servedFor time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if servedFor > 0 {
+		c.stale[key] = c.now().Add(servedFor)
+	} else {
+		delete(c.stale, key)
+	}
+}
+
+// isPreview returns true if the request is being made on behalf of a publishing user, either
+// because it carries their auth token or because it is scoped to a collection
+func isPreview(ctx context.Context, userAuthToken string) bool {
+	if userAuthToken != "" {
+		return true
+	}
+	collectionID, _ := ctx.Value(dprequest.CollectionIDContextKey).(string)
+	return collectionID != ""
+}
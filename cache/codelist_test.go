@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCodeListClient(t *testing.T) {
+
+	Convey("Given a caching code list client", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+
+		ctx := context.Background()
+		mockClient := &handlers.CodeListClientMock{
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				return codelist.EditionsListResults{
+					Items: []codelist.EditionsList{{Edition: "2018", Label: "Local authority districts"}},
+					Count: 1,
+				}, nil
+			},
+			GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				return codelist.CodesResults{}, errors.New("code-list api unavailable")
+			},
+		}
+		cli := NewCodeListClient(mockClient, cfg)
+
+		Convey("repeated public requests only call the code list API once", func() {
+			first, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			So(err, ShouldBeNil)
+			second, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			So(err, ShouldBeNil)
+
+			So(second, ShouldResemble, first)
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 1)
+
+			Convey("and requests for a different code list are not served from the cache", func() {
+				_, err := cli.GetCodeListEditions(ctx, "", "", "countries")
+				So(err, ShouldBeNil)
+				So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("requests with a user auth token bypass the cache", func() {
+			cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			cli.GetCodeListEditions(ctx, "florence-token", "", "local-authority")
+			cli.GetCodeListEditions(ctx, "florence-token", "", "local-authority")
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 3)
+		})
+
+		Convey("requests for a collection bypass the cache", func() {
+			previewCtx := context.WithValue(ctx, dprequest.CollectionIDContextKey, "my-collection")
+			cli.GetCodeListEditions(previewCtx, "", "", "local-authority")
+			cli.GetCodeListEditions(previewCtx, "", "", "local-authority")
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 2)
+		})
+
+		Convey("errors are not cached", func() {
+			_, err := cli.GetCodes(ctx, "", "", "local-authority", "2018")
+			So(err, ShouldNotBeNil)
+			_, err = cli.GetCodes(ctx, "", "", "local-authority", "2018")
+			So(err, ShouldNotBeNil)
+			So(mockClient.GetCodesCalls(), ShouldHaveLength, 2)
+		})
+	})
+}
+
+func TestCodeListClientStale(t *testing.T) {
+
+	Convey("Given a caching code list client that serves stale data for a while after it expires", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		cfg.EditionsCacheTTL = time.Minute
+		cfg.CacheStaleWhileRevalidate = 10 * time.Minute
+		cfg.CacheStaleIfError = time.Hour
+
+		ctx := context.Background()
+		edition := "2018"
+		var apiErr error
+		refreshing := make(chan struct{})
+		mockClient := &handlers.CodeListClientMock{
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				if refreshing != nil {
+					<-refreshing
+				}
+				if apiErr != nil {
+					return codelist.EditionsListResults{}, apiErr
+				}
+				return codelist.EditionsListResults{Items: []codelist.EditionsList{{Edition: edition}}, Count: 1}, nil
+			},
+		}
+
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		cli := NewCodeListClient(mockClient, cfg)
+		cli.now = func() time.Time { return now }
+		cli.editions.now = cli.now
+
+		close(refreshing)
+		_, err = cli.GetCodeListEditions(ctx, "", "", "local-authority")
+		So(err, ShouldBeNil)
+		refreshing = make(chan struct{})
+		edition = "2019"
+
+		checkState := func() string {
+			state := health.NewCheckState("geography cache")
+			So(cli.Checker(ctx, state), ShouldBeNil)
+			return state.Status()
+		}
+
+		Convey("an expired entry is served while a single background request refreshes it", func() {
+			now = now.Add(5 * time.Minute)
+			for i := 0; i < 3; i++ {
+				editions, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+				So(err, ShouldBeNil)
+				So(editions.Items[0].Edition, ShouldEqual, "2018")
+			}
+
+			close(refreshing)
+			waitFor(func() bool {
+				editions, _, _ := cli.editions.Lookup("/code-lists/local-authority/editions")
+				return editions.(codelist.EditionsListResults).Items[0].Edition == "2019"
+			})
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 2)
+
+			editions, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			So(err, ShouldBeNil)
+			So(editions.Items[0].Edition, ShouldEqual, "2019")
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 2)
+			So(checkState(), ShouldEqual, health.StatusOK)
+		})
+
+		Convey("a purge while the background request is refreshing an entry stops the refreshed entry being stored", func() {
+			now = now.Add(5 * time.Minute)
+			editions, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			So(err, ShouldBeNil)
+			So(editions.Items[0].Edition, ShouldEqual, "2018")
+
+			So(cli.PurgeCodeList("local-authority"), ShouldEqual, 1)
+			close(refreshing)
+			waitFor(func() bool {
+				cli.mutex.Lock()
+				defer cli.mutex.Unlock()
+				return len(cli.refreshing) == 0
+			})
+			_, _, ok := cli.editions.Lookup("/code-lists/local-authority/editions")
+			So(ok, ShouldBeFalse)
+
+			editions, err = cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			So(err, ShouldBeNil)
+			So(editions.Items[0].Edition, ShouldEqual, "2019")
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 3)
+		})
+
+		Convey("when the code list API fails", func() {
+			close(refreshing)
+			apiErr = errors.New("code-list api unavailable")
+
+			Convey("an entry that expired within the stale-if-error period is served instead of the error", func() {
+				now = now.Add(30 * time.Minute)
+				editions, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+				So(err, ShouldBeNil)
+				So(editions.Items[0].Edition, ShouldEqual, "2018")
+				So(checkState(), ShouldEqual, health.StatusWarning)
+
+				Convey("and the health check recovers once the entry is refreshed", func() {
+					apiErr = nil
+					editions, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+					So(err, ShouldBeNil)
+					So(editions.Items[0].Edition, ShouldEqual, "2019")
+					So(checkState(), ShouldEqual, health.StatusOK)
+				})
+
+				Convey("and the health check recovers once the entry can no longer be served", func() {
+					now = now.Add(time.Hour)
+					So(checkState(), ShouldEqual, health.StatusOK)
+				})
+			})
+
+			Convey("the error is returned once the stale-if-error period has passed", func() {
+				now = now.Add(2 * time.Hour)
+				_, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+				So(err, ShouldEqual, apiErr)
+				So(checkState(), ShouldEqual, health.StatusOK)
+			})
+		})
+	})
+}
+
+// waitFor polls condition until it is true, or fails the test after a second
+func waitFor(condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			So(condition(), ShouldBeTrue)
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
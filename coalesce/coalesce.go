@@ -0,0 +1,179 @@
+package coalesce
+
+import (
+	"context"
+	"expvar"
+	"strings"
+	"sync"
+	"time"
+
+	dprequest "github.com/ONSdigital/dp-net/request"
+)
+
+// Metrics counts, for each coalesced method, the calls made to it and how many of those were deduplicated by
+// sharing a request that was already in flight. It is published in /debug/vars as "coalesce", with keys of the form
+// "<client>.<method>.calls" and "<client>.<method>.deduplicated".
+var Metrics = expvar.NewMap("coalesce")
+
+// Observer is told of each call made to a coalesced method of a client, and whether it was deduplicated by sharing a
+// request that was already in flight
+type Observer func(client, method string, deduplicated bool)
+
+// group shares one in-flight call between concurrent callers with the same key
+type group struct {
+	name    string
+	timeout time.Duration
+	observe Observer
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is a downstream call in flight, and the callers waiting for its result
+type call struct {
+	ctx     *callContext
+	waiters int
+	val     interface{}
+	err     error
+	done    chan struct{}
+}
+
+// do calls fn, unless an identical call is already in flight, in which case it waits for and returns that call's
+// result. The key identifies the method, its arguments and the auth context of the call, so that responses are
+// only ever shared between callers that would have been given the same response. The shared call runs with the
+// values of the context of the caller that started it, such as its collection ID, and the latest deadline of the
+// callers waiting for it, each bounded by the group's timeout, so that the caller that started it giving up does
+// not fail the others. Every caller stops waiting when its own context is done, and the call is cancelled once
+// every caller has stopped waiting for it.
+func (g *group) do(ctx context.Context, method, userAuthToken, serviceAuthToken string, args []string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	metric := g.name + "." + method
+	Metrics.Add(metric+".calls", 1)
+
+	collectionID, _ := ctx.Value(dprequest.CollectionIDContextKey).(string)
+	key := strings.Join(append([]string{method, userAuthToken, serviceAuthToken, collectionID}, args...), "\x00")
+
+	deadline := time.Now().Add(g.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	g.mu.Lock()
+	c, shared := g.calls[key]
+	if shared && c.ctx.extend(deadline) {
+		c.waiters++
+	} else {
+		shared = false
+		c = &call{ctx: newCallContext(ctx, deadline), waiters: 1, done: make(chan struct{})}
+		if g.calls == nil {
+			g.calls = make(map[string]*call)
+		}
+		g.calls[key] = c
+		go g.run(key, c, fn)
+	}
+	g.mu.Unlock()
+
+	if shared {
+		Metrics.Add(metric+".deduplicated", 1)
+	}
+	if g.observe != nil {
+		g.observe(g.name, method, shared)
+	}
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		g.leave(key, c)
+		return nil, ctx.Err()
+	}
+}
+
+// run makes the shared call, then removes it from the group and hands its result to the callers waiting for it
+func (g *group) run(key string, c *call, fn func(ctx context.Context) (interface{}, error)) {
+	c.val, c.err = fn(c.ctx)
+	g.mu.Lock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	c.ctx.cancel(context.Canceled)
+	close(c.done)
+}
+
+// leave stops a caller waiting for c, and cancels c once no caller is waiting for it
+func (g *group) leave(key string, c *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c.waiters--
+	if c.waiters > 0 {
+		return
+	}
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	c.ctx.cancel(context.Canceled)
+}
+
+// callContext is the context of a shared call. It has the values of the context of the caller that started the
+// call, but its own deadline, which is extended as callers with later deadlines join the call, and its own
+// cancellation.
+type callContext struct {
+	parent context.Context
+	done   chan struct{}
+
+	mu       sync.Mutex
+	deadline time.Time
+	timer    *time.Timer
+	err      error
+}
+
+func newCallContext(parent context.Context, deadline time.Time) *callContext {
+	c := &callContext{parent: parent, done: make(chan struct{}), deadline: deadline}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timer = time.AfterFunc(time.Until(deadline), func() { c.cancel(context.DeadlineExceeded) })
+	return c
+}
+
+// extend moves the deadline of the call to deadline, if that is later. It returns false if the call has already
+// been cancelled, so cannot be joined.
+func (c *callContext) extend(deadline time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return false
+	}
+	if deadline.After(c.deadline) {
+		c.deadline = deadline
+		c.timer.Reset(time.Until(deadline))
+	}
+	return true
+}
+
+// cancel ends the call with err, unless it has already ended
+func (c *callContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	c.timer.Stop()
+	close(c.done)
+}
+
+func (c *callContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, true
+}
+
+func (c *callContext) Done() <-chan struct{} { return c.done }
+
+func (c *callContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *callContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
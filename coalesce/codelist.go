@@ -0,0 +1,70 @@
+package coalesce
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+// CodeListClient is a handlers.CodeListClient that shares one in-flight request between concurrent identical calls
+// to the client it wraps
+type CodeListClient struct {
+	client handlers.CodeListClient
+	group  *group
+}
+
+// NewCodeListClient wraps the provided client so that concurrent identical calls are coalesced, each shared call
+// taking no longer than timeout. Each call is reported to observe, if it is not nil.
+func NewCodeListClient(client handlers.CodeListClient, timeout time.Duration, observe Observer) *CodeListClient {
+	return &CodeListClient{
+		client: client,
+		group:  &group{name: "codelist", timeout: timeout, observe: observe},
+	}
+}
+
+// GetGeographyCodeLists returns the geography code lists, sharing any identical request in flight
+func (c *CodeListClient) GetGeographyCodeLists(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+	v, err := c.group.do(ctx, "GetGeographyCodeLists", userAuthToken, serviceAuthToken, nil, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetGeographyCodeLists(ctx, userAuthToken, serviceAuthToken)
+	})
+	results, _ := v.(codelist.CodeListResults)
+	return results, err
+}
+
+// GetCodeListEditions returns the editions of a code list, sharing any identical request in flight
+func (c *CodeListClient) GetCodeListEditions(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+	v, err := c.group.do(ctx, "GetCodeListEditions", userAuthToken, serviceAuthToken, []string{codeListID}, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
+	})
+	editions, _ := v.(codelist.EditionsListResults)
+	return editions, err
+}
+
+// GetCodes returns the codes of an edition of a code list, sharing any identical request in flight
+func (c *CodeListClient) GetCodes(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+	v, err := c.group.do(ctx, "GetCodes", userAuthToken, serviceAuthToken, []string{codeListID, edition}, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetCodes(ctx, userAuthToken, serviceAuthToken, codeListID, edition)
+	})
+	codes, _ := v.(codelist.CodesResults)
+	return codes, err
+}
+
+// GetCodeByID returns a code of an edition of a code list, sharing any identical request in flight
+func (c *CodeListClient) GetCodeByID(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+	v, err := c.group.do(ctx, "GetCodeByID", userAuthToken, serviceAuthToken, []string{codeListID, edition, codeID}, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetCodeByID(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+	})
+	code, _ := v.(codelist.CodeResult)
+	return code, err
+}
+
+// GetDatasetsByCode returns the datasets related to a code, sharing any identical request in flight
+func (c *CodeListClient) GetDatasetsByCode(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+	v, err := c.group.do(ctx, "GetDatasetsByCode", userAuthToken, serviceAuthToken, []string{codeListID, edition, codeID}, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetDatasetsByCode(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+	})
+	datasets, _ := v.(codelist.DatasetsResult)
+	return datasets, err
+}
@@ -0,0 +1,282 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func metric(key string) int64 {
+	if v, ok := Metrics.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// observed counts the calls reported to an Observer
+type observed struct {
+	sync.Mutex
+	calls, deduplicated int
+}
+
+func (o *observed) observe(client, method string, deduplicated bool) {
+	o.Lock()
+	defer o.Unlock()
+	o.calls++
+	if deduplicated {
+		o.deduplicated++
+	}
+}
+
+// waitForCalls waits until the metric for the calls to a method reaches n, then gives the callers a moment to join
+// the call in flight
+func waitForCalls(key string, n int64) {
+	for metric(key) < n {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestCodeListClient(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a coalescing code list client and a downstream call that blocks until released", t, func() {
+		release := make(chan struct{})
+		mockClient := &handlers.CodeListClientMock{
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				<-release
+				return codelist.EditionsListResults{Count: 1, Items: []codelist.EditionsList{{Edition: "2018"}}}, nil
+			},
+		}
+		observer := &observed{}
+		cli := NewCodeListClient(mockClient, time.Minute, observer.observe)
+		calls := metric("codelist.GetCodeListEditions.calls")
+		deduplicated := metric("codelist.GetCodeListEditions.deduplicated")
+
+		Convey("concurrent identical calls share one downstream request and all get its result", func() {
+			var wg sync.WaitGroup
+			results := make([]codelist.EditionsListResults, 5)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], _ = cli.GetCodeListEditions(ctx, "user", "service", "local-authority")
+				}(i)
+			}
+			waitForCalls("codelist.GetCodeListEditions.calls", calls+5)
+			close(release)
+			wg.Wait()
+
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 1)
+			for _, result := range results {
+				So(result.Items[0].Edition, ShouldEqual, "2018")
+			}
+			So(metric("codelist.GetCodeListEditions.calls")-calls, ShouldEqual, 5)
+			So(metric("codelist.GetCodeListEditions.deduplicated")-deduplicated, ShouldEqual, 4)
+			So(observer.calls, ShouldEqual, 5)
+			So(observer.deduplicated, ShouldEqual, 4)
+		})
+
+		Convey("calls with different arguments or auth context are not shared", func() {
+			previewCtx := context.WithValue(ctx, dprequest.CollectionIDContextKey, "collection")
+			var wg sync.WaitGroup
+			for _, call := range []func(){
+				func() { cli.GetCodeListEditions(ctx, "user", "service", "local-authority") },
+				func() { cli.GetCodeListEditions(ctx, "user", "service", "region") },
+				func() { cli.GetCodeListEditions(ctx, "other-user", "service", "local-authority") },
+				func() { cli.GetCodeListEditions(previewCtx, "user", "service", "local-authority") },
+			} {
+				wg.Add(1)
+				go func(call func()) {
+					defer wg.Done()
+					call()
+				}(call)
+			}
+			waitForCalls("codelist.GetCodeListEditions.calls", calls+4)
+			close(release)
+			wg.Wait()
+
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 4)
+			So(metric("codelist.GetCodeListEditions.deduplicated")-deduplicated, ShouldEqual, 0)
+		})
+
+		Convey("a caller whose context is done stops waiting without affecting the call in flight", func() {
+			var wg sync.WaitGroup
+			var result codelist.EditionsListResults
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, _ = cli.GetCodeListEditions(ctx, "user", "service", "local-authority")
+			}()
+			waitForCalls("codelist.GetCodeListEditions.calls", calls+1)
+
+			cancelledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := cli.GetCodeListEditions(cancelledCtx, "user", "service", "local-authority")
+			So(err, ShouldEqual, context.Canceled)
+
+			close(release)
+			wg.Wait()
+			So(result.Items[0].Edition, ShouldEqual, "2018")
+		})
+		Convey("the caller that started the call giving up does not fail the callers waiting for it", func() {
+			callErrs := make(chan error, 1)
+			mockClient.GetCodeListEditionsFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				<-release
+				callErrs <- ctx.Err()
+				return codelist.EditionsListResults{Count: 1, Items: []codelist.EditionsList{{Edition: "2018"}}}, nil
+			}
+			leaderCtx, cancelLeader := context.WithCancel(context.WithValue(ctx, dprequest.CollectionIDContextKey, "collection"))
+			leaderErr := make(chan error, 1)
+			go func() {
+				_, err := cli.GetCodeListEditions(leaderCtx, "user", "service", "local-authority")
+				leaderErr <- err
+			}()
+			waitForCalls("codelist.GetCodeListEditions.calls", calls+1)
+
+			var wg sync.WaitGroup
+			var result codelist.EditionsListResults
+			var err error
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				waiterCtx := context.WithValue(ctx, dprequest.CollectionIDContextKey, "collection")
+				result, err = cli.GetCodeListEditions(waiterCtx, "user", "service", "local-authority")
+			}()
+			waitForCalls("codelist.GetCodeListEditions.calls", calls+2)
+
+			cancelLeader()
+			So(<-leaderErr, ShouldEqual, context.Canceled)
+			close(release)
+			wg.Wait()
+
+			So(err, ShouldBeNil)
+			So(result.Items[0].Edition, ShouldEqual, "2018")
+			calls := mockClient.GetCodeListEditionsCalls()
+			So(calls, ShouldHaveLength, 1)
+			So(<-callErrs, ShouldBeNil)
+			So(calls[0].Ctx.Value(dprequest.CollectionIDContextKey), ShouldEqual, "collection")
+		})
+	})
+
+	Convey("Given a coalescing code list client and a downstream call that waits for its context to be done", t, func() {
+		callCtxs := make(chan context.Context, 1)
+		mockClient := &handlers.CodeListClientMock{
+			GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+				callCtxs <- ctx
+				<-ctx.Done()
+				return codelist.CodeListResults{}, ctx.Err()
+			},
+		}
+		cli := NewCodeListClient(mockClient, time.Minute, nil)
+		calls := metric("codelist.GetGeographyCodeLists.calls")
+
+		Convey("the shared call is bounded by the timeout of the client when its caller has no deadline", func() {
+			callerCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go cli.GetGeographyCodeLists(callerCtx, "user", "service")
+
+			deadline, ok := (<-callCtxs).Deadline()
+			So(ok, ShouldBeTrue)
+			So(deadline, ShouldHappenWithin, time.Second, time.Now().Add(time.Minute))
+		})
+
+		Convey("the shared call has the latest deadline of the callers waiting for it", func() {
+			firstCtx, cancelFirst := context.WithTimeout(ctx, 10*time.Second)
+			defer cancelFirst()
+			go cli.GetGeographyCodeLists(firstCtx, "user", "service")
+			callCtx := <-callCtxs
+			deadline, _ := callCtx.Deadline()
+			So(deadline, ShouldHappenWithin, time.Second, time.Now().Add(10*time.Second))
+
+			laterCtx, cancelLater := context.WithTimeout(ctx, 20*time.Second)
+			defer cancelLater()
+			go cli.GetGeographyCodeLists(laterCtx, "user", "service")
+			waitForCalls("codelist.GetGeographyCodeLists.calls", calls+2)
+
+			deadline, _ = callCtx.Deadline()
+			So(deadline, ShouldHappenWithin, time.Second, time.Now().Add(20*time.Second))
+			So(callCtx.Err(), ShouldBeNil)
+		})
+
+		Convey("the shared call is cancelled once every caller has stopped waiting for it", func() {
+			firstCtx, cancelFirst := context.WithCancel(ctx)
+			firstErr := make(chan error, 1)
+			go func() {
+				_, err := cli.GetGeographyCodeLists(firstCtx, "user", "service")
+				firstErr <- err
+			}()
+			callCtx := <-callCtxs
+
+			secondCtx, cancelSecond := context.WithCancel(ctx)
+			secondErr := make(chan error, 1)
+			go func() {
+				_, err := cli.GetGeographyCodeLists(secondCtx, "user", "service")
+				secondErr <- err
+			}()
+			waitForCalls("codelist.GetGeographyCodeLists.calls", calls+2)
+
+			cancelFirst()
+			So(<-firstErr, ShouldEqual, context.Canceled)
+			So(callCtx.Err(), ShouldBeNil)
+
+			cancelSecond()
+			So(<-secondErr, ShouldEqual, context.Canceled)
+			<-callCtx.Done()
+			So(callCtx.Err(), ShouldEqual, context.Canceled)
+		})
+
+		Convey("the shared call ends when the deadline of its only caller has passed", func() {
+			callerCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+
+			_, err := cli.GetGeographyCodeLists(callerCtx, "user", "service")
+
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			callCtx := <-callCtxs
+			<-callCtx.Done()
+			So(callCtx.Err(), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a coalescing code list client and a downstream call that fails", t, func() {
+		errCodes := errors.New("code list api unavailable")
+		release := make(chan struct{})
+		mockClient := &handlers.CodeListClientMock{
+			GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				<-release
+				return codelist.CodesResults{}, errCodes
+			},
+		}
+		cli := NewCodeListClient(mockClient, time.Minute, nil)
+		calls := metric("codelist.GetCodes.calls")
+
+		Convey("the error is returned to every caller sharing the call", func() {
+			var wg sync.WaitGroup
+			errs := make([]error, 3)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = cli.GetCodes(ctx, "", "service", "local-authority", "2018")
+				}(i)
+			}
+			waitForCalls("codelist.GetCodes.calls", calls+3)
+			close(release)
+			wg.Wait()
+
+			So(mockClient.GetCodesCalls(), ShouldHaveLength, 1)
+			for _, err := range errs {
+				So(err, ShouldEqual, errCodes)
+			}
+		})
+	})
+}
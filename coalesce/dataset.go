@@ -0,0 +1,43 @@
+package coalesce
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/dataset"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+// DatasetClient is a handlers.DatasetClient that shares one in-flight request between concurrent identical calls
+// to the client it wraps
+type DatasetClient struct {
+	client handlers.DatasetClient
+	group  *group
+}
+
+// NewDatasetClient wraps the provided client so that concurrent identical calls are coalesced, each shared call
+// taking no longer than timeout. Each call is reported to observe, if it is not nil.
+func NewDatasetClient(client handlers.DatasetClient, timeout time.Duration, observe Observer) *DatasetClient {
+	return &DatasetClient{
+		client: client,
+		group:  &group{name: "dataset", timeout: timeout, observe: observe},
+	}
+}
+
+// Get returns the details of a dataset, sharing any identical request in flight
+func (c *DatasetClient) Get(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID string) (dataset.DatasetDetails, error) {
+	v, err := c.group.do(ctx, "Get", userAuthToken, serviceAuthToken, []string{collectionID, datasetID}, func(ctx context.Context) (interface{}, error) {
+		return c.client.Get(ctx, userAuthToken, serviceAuthToken, collectionID, datasetID)
+	})
+	details, _ := v.(dataset.DatasetDetails)
+	return details, err
+}
+
+// GetVersion returns a version of an edition of a dataset, sharing any identical request in flight
+func (c *DatasetClient) GetVersion(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (dataset.Version, error) {
+	v, err := c.group.do(ctx, "GetVersion", userAuthToken, serviceAuthToken, []string{downloadServiceAuthToken, collectionID, datasetID, edition, version}, func(ctx context.Context) (interface{}, error) {
+		return c.client.GetVersion(ctx, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version)
+	})
+	m, _ := v.(dataset.Version)
+	return m, err
+}
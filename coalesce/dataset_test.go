@@ -0,0 +1,50 @@
+package coalesce
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/dataset"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDatasetClient(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a coalescing dataset client and a downstream call that blocks until released", t, func() {
+		release := make(chan struct{})
+		mockClient := &handlers.DatasetClientMock{
+			GetFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string) (dataset.DatasetDetails, error) {
+				<-release
+				return dataset.DatasetDetails{ID: datasetID}, nil
+			},
+		}
+		cli := NewDatasetClient(mockClient, time.Minute, nil)
+		calls := metric("dataset.Get.calls")
+		deduplicated := metric("dataset.Get.deduplicated")
+
+		Convey("concurrent calls for the same dataset share one downstream request", func() {
+			var wg sync.WaitGroup
+			results := make([]dataset.DatasetDetails, 3)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], _ = cli.Get(ctx, "", "service", "", "cpih01")
+				}(i)
+			}
+			waitForCalls("dataset.Get.calls", calls+3)
+			close(release)
+			wg.Wait()
+
+			So(mockClient.GetCalls(), ShouldHaveLength, 1)
+			for _, result := range results {
+				So(result.ID, ShouldEqual, "cpih01")
+			}
+			So(metric("dataset.Get.deduplicated")-deduplicated, ShouldEqual, 2)
+		})
+	})
+}
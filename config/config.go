@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -10,12 +11,87 @@ var cfg *Config
 
 //Config represents service configuration for dp-frontend-geography-controller
 type Config struct {
-	BindAddr                   string        `envconfig:"BIND_ADDR"`
-	APIRouterURL               string        `envconfig:"API_ROUTER_URL"`
-	RendererURL                string        `envconfig:"RENDERER_URL"`
-	GracefulShutdownTimeout    time.Duration `envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT"`
-	HealthCheckInterval        time.Duration `envconfig:"HEALTHCHECK_INTERVAL"`
-	HealthCheckCriticalTimeout time.Duration `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
+	BindAddr                     string        `envconfig:"BIND_ADDR"`
+	APIRouterURL                 string        `envconfig:"API_ROUTER_URL"`
+	RendererURL                  string        `envconfig:"RENDERER_URL"`
+	GracefulShutdownTimeout      time.Duration `envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT"`
+	HealthCheckInterval          time.Duration `envconfig:"HEALTHCHECK_INTERVAL"`
+	HealthCheckCriticalTimeout   time.Duration `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
+	CodeListsCacheTTL            time.Duration `envconfig:"CODE_LISTS_CACHE_TTL"`
+	CodeListsCacheMaxSize        int           `envconfig:"CODE_LISTS_CACHE_MAX_SIZE"`
+	EditionsCacheTTL             time.Duration `envconfig:"EDITIONS_CACHE_TTL"`
+	EditionsCacheMaxSize         int           `envconfig:"EDITIONS_CACHE_MAX_SIZE"`
+	CodesCacheTTL                time.Duration `envconfig:"CODES_CACHE_TTL"`
+	CodesCacheMaxSize            int           `envconfig:"CODES_CACHE_MAX_SIZE"`
+	CodeCacheTTL                 time.Duration `envconfig:"CODE_CACHE_TTL"`
+	CodeCacheMaxSize             int           `envconfig:"CODE_CACHE_MAX_SIZE"`
+	DatasetsByCodeCacheTTL       time.Duration `envconfig:"DATASETS_BY_CODE_CACHE_TTL"`
+	DatasetsByCodeCacheMaxSize   int           `envconfig:"DATASETS_BY_CODE_CACHE_MAX_SIZE"`
+	CacheStaleWhileRevalidate    time.Duration `envconfig:"CACHE_STALE_WHILE_REVALIDATE"`
+	CacheStaleIfError            time.Duration `envconfig:"CACHE_STALE_IF_ERROR"`
+	ListPageDefaultLimit         int           `envconfig:"LIST_PAGE_DEFAULT_LIMIT"`
+	ListPageMaxLimit             int           `envconfig:"LIST_PAGE_MAX_LIMIT"`
+	CodeListAPIPageLimit         int           `envconfig:"CODE_LIST_API_PAGE_LIMIT"`
+	CodeListAPIPagesInFlight     int           `envconfig:"CODE_LIST_API_PAGES_IN_FLIGHT"`
+	AreaPageDatasetFailurePolicy string        `envconfig:"AREA_PAGE_DATASET_FAILURE_POLICY"`
+	HomepageWorkers              int           `envconfig:"HOMEPAGE_WORKERS"`
+	HomepageCallTimeout          time.Duration `envconfig:"HOMEPAGE_CALL_TIMEOUT"`
+	AreaPageWorkers              int           `envconfig:"AREA_PAGE_WORKERS"`
+	AreaPageCallTimeout          time.Duration `envconfig:"AREA_PAGE_CALL_TIMEOUT"`
+	RequestBudget                time.Duration `envconfig:"REQUEST_BUDGET"`
+	CoalesceCallTimeout          time.Duration `envconfig:"COALESCE_CALL_TIMEOUT"`
+	CodeListBreakerThreshold     int           `envconfig:"CODE_LIST_BREAKER_THRESHOLD"`
+	DatasetBreakerThreshold      int           `envconfig:"DATASET_BREAKER_THRESHOLD"`
+	RendererBreakerThreshold     int           `envconfig:"RENDERER_BREAKER_THRESHOLD"`
+	BreakerOpenTimeout           time.Duration `envconfig:"BREAKER_OPEN_TIMEOUT"`
+	RetryMaxAttempts             int           `envconfig:"RETRY_MAX_ATTEMPTS"`
+	RetryInitialBackoff          time.Duration `envconfig:"RETRY_INITIAL_BACKOFF"`
+	RetryMaxBackoff              time.Duration `envconfig:"RETRY_MAX_BACKOFF"`
+	HomepageCacheControl         CachePolicy   `envconfig:"HOMEPAGE_CACHE_CONTROL"`
+	ListPageCacheControl         CachePolicy   `envconfig:"LIST_PAGE_CACHE_CONTROL"`
+	AreaPageCacheControl         CachePolicy   `envconfig:"AREA_PAGE_CACHE_CONTROL"`
+	CSVCacheControl              CachePolicy   `envconfig:"CSV_CACHE_CONTROL"`
+	PageCacheTTL                 time.Duration `envconfig:"PAGE_CACHE_TTL"`
+	PageCacheMaxBytes            int           `envconfig:"PAGE_CACHE_MAX_BYTES"`
+	AdminBindAddr                string        `envconfig:"ADMIN_BIND_ADDR"`
+	AdminSecret                  string        `envconfig:"ADMIN_SECRET" json:"-"`
+	CacheWarmerEnabled           bool          `envconfig:"CACHE_WARMER_ENABLED"`
+	CacheWarmerInterval          time.Duration `envconfig:"CACHE_WARMER_INTERVAL"`
+	CacheWarmerConcurrency       int           `envconfig:"CACHE_WARMER_CONCURRENCY"`
+	CacheWarmerHoldReadiness     bool          `envconfig:"CACHE_WARMER_HOLD_READINESS"`
+	KafkaEnabled                 bool          `envconfig:"KAFKA_ENABLED"`
+	KafkaAddr                    []string      `envconfig:"KAFKA_ADDR"`
+	KafkaChangeEventsTopic       string        `envconfig:"KAFKA_CHANGE_EVENTS_TOPIC"`
+	KafkaConsumerGroup           string        `envconfig:"KAFKA_CONSUMER_GROUP"`
+	OTelEnabled                  bool          `envconfig:"OTEL_ENABLED"`
+	OTelServiceName              string        `envconfig:"OTEL_SERVICE_NAME"`
+	OTelExporterOTLPEndpoint     string        `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTelExporterOTLPInsecure     bool          `envconfig:"OTEL_EXPORTER_OTLP_INSECURE"`
+	OTelBatchTimeout             time.Duration `envconfig:"OTEL_BATCH_TIMEOUT"`
+	OTelSampleRatio              float64       `envconfig:"OTEL_SAMPLE_RATIO"`
+}
+
+// CachePolicy is how long the successful public responses of a route can be cached for. Each is set from environment
+// variables prefixed with the name of the route's field, e.g. HOMEPAGE_CACHE_CONTROL_MAX_AGE.
+type CachePolicy struct {
+	MaxAge               time.Duration `envconfig:"MAX_AGE"`
+	SMaxAge              time.Duration `envconfig:"S_MAXAGE"`
+	StaleWhileRevalidate time.Duration `envconfig:"STALE_WHILE_REVALIDATE"`
+}
+
+// Policies for rendering the area page when some of its datasets cannot be retrieved
+const (
+	// DatasetFailurePolicyFail responds with an error page
+	DatasetFailurePolicyFail = "fail"
+	// DatasetFailurePolicyPartial renders the datasets that could be retrieved and flags that some are unavailable
+	DatasetFailurePolicyPartial = "partial"
+)
+
+// defaultCachePolicy lets browsers cache pages briefly, and shared caches for longer
+var defaultCachePolicy = CachePolicy{
+	MaxAge:               5 * time.Minute,
+	SMaxAge:              15 * time.Minute,
+	StaleWhileRevalidate: time.Minute,
 }
 
 // Get returns the default config with any modifications through environment
@@ -23,13 +99,95 @@ type Config struct {
 func Get() (cfg *Config, err error) {
 
 	cfg = &Config{
-		BindAddr:                   ":23700",
-		APIRouterURL:               "http://localhost:23200/v1",
-		RendererURL:                "http://localhost:20010",
-		GracefulShutdownTimeout:    5 * time.Second,
-		HealthCheckInterval:        30 * time.Second,
-		HealthCheckCriticalTimeout: 90 * time.Second,
+		BindAddr:                     ":23700",
+		APIRouterURL:                 "http://localhost:23200/v1",
+		RendererURL:                  "http://localhost:20010",
+		GracefulShutdownTimeout:      5 * time.Second,
+		HealthCheckInterval:          30 * time.Second,
+		HealthCheckCriticalTimeout:   90 * time.Second,
+		CodeListsCacheTTL:            time.Hour,
+		CodeListsCacheMaxSize:        1,
+		EditionsCacheTTL:             time.Hour,
+		EditionsCacheMaxSize:         500,
+		CodesCacheTTL:                time.Hour,
+		CodesCacheMaxSize:            100,
+		CodeCacheTTL:                 time.Hour,
+		CodeCacheMaxSize:             10000,
+		DatasetsByCodeCacheTTL:       time.Hour,
+		DatasetsByCodeCacheMaxSize:   10000,
+		CacheStaleWhileRevalidate:    time.Hour,
+		CacheStaleIfError:            24 * time.Hour,
+		ListPageDefaultLimit:         100,
+		ListPageMaxLimit:             1000,
+		CodeListAPIPageLimit:         1000,
+		CodeListAPIPagesInFlight:     4,
+		AreaPageDatasetFailurePolicy: DatasetFailurePolicyFail,
+		HomepageWorkers:              10,
+		HomepageCallTimeout:          5 * time.Second,
+		AreaPageWorkers:              10,
+		AreaPageCallTimeout:          5 * time.Second,
+		RequestBudget:                20 * time.Second,
+		CoalesceCallTimeout:          30 * time.Second,
+		CodeListBreakerThreshold:     5,
+		DatasetBreakerThreshold:      5,
+		RendererBreakerThreshold:     5,
+		BreakerOpenTimeout:           10 * time.Second,
+		RetryMaxAttempts:             3,
+		RetryInitialBackoff:          100 * time.Millisecond,
+		RetryMaxBackoff:              2 * time.Second,
+		HomepageCacheControl:         defaultCachePolicy,
+		ListPageCacheControl:         defaultCachePolicy,
+		AreaPageCacheControl:         defaultCachePolicy,
+		CSVCacheControl:              defaultCachePolicy,
+		PageCacheTTL:                 time.Minute,
+		CacheWarmerEnabled:           true,
+		CacheWarmerInterval:          30 * time.Minute,
+		CacheWarmerConcurrency:       4,
+		KafkaAddr:                    []string{"localhost:9092"},
+		KafkaChangeEventsTopic:       "geography-change-events",
+		KafkaConsumerGroup:           "dp-frontend-geography-controller",
+		OTelServiceName:              "dp-frontend-geography-controller",
+		OTelExporterOTLPEndpoint:     "localhost:4318",
+		OTelExporterOTLPInsecure:     true,
+		OTelBatchTimeout:             5 * time.Second,
+		OTelSampleRatio:              1,
+	}
+
+	if err := envconfig.Process("", cfg); err != nil {
+		return cfg, err
+	}
+
+	switch cfg.AreaPageDatasetFailurePolicy {
+	case DatasetFailurePolicyFail, DatasetFailurePolicyPartial:
+	default:
+		return cfg, fmt.Errorf("invalid AREA_PAGE_DATASET_FAILURE_POLICY %q, must be %q or %q", cfg.AreaPageDatasetFailurePolicy, DatasetFailurePolicyFail, DatasetFailurePolicyPartial)
+	}
+
+	if cfg.OTelSampleRatio < 0 || cfg.OTelSampleRatio > 1 {
+		return cfg, fmt.Errorf("invalid OTEL_SAMPLE_RATIO %v, must be between 0 and 1", cfg.OTelSampleRatio)
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{"LIST_PAGE_DEFAULT_LIMIT", cfg.ListPageDefaultLimit},
+		{"LIST_PAGE_MAX_LIMIT", cfg.ListPageMaxLimit},
+		{"CODE_LIST_API_PAGE_LIMIT", cfg.CodeListAPIPageLimit},
+		{"CODE_LIST_API_PAGES_IN_FLIGHT", cfg.CodeListAPIPagesInFlight},
+	} {
+		if setting.value <= 0 {
+			return cfg, fmt.Errorf("invalid %s %d, must be greater than 0", setting.name, setting.value)
+		}
+	}
+
+	if cfg.ListPageDefaultLimit > cfg.ListPageMaxLimit {
+		return cfg, fmt.Errorf("invalid LIST_PAGE_DEFAULT_LIMIT %d, must be no larger than LIST_PAGE_MAX_LIMIT %d", cfg.ListPageDefaultLimit, cfg.ListPageMaxLimit)
+	}
+
+	if cfg.CoalesceCallTimeout <= 0 {
+		return cfg, fmt.Errorf("invalid COALESCE_CALL_TIMEOUT %v, must be greater than 0", cfg.CoalesceCallTimeout)
 	}
 
-	return cfg, envconfig.Process("", cfg)
+	return cfg, nil
 }
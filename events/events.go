@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// Type is the kind of change that an event notifies
+type Type string
+
+// Types of change event
+const (
+	CodeListPublished       Type = "code-list-published"
+	EditionPublished        Type = "edition-published"
+	DatasetVersionPublished Type = "dataset-version-published"
+)
+
+// Event notifies a change to data that the service caches. Which fields are set depends on its type: a code list
+// event has a code list ID, an edition event also has an edition, and a dataset version event has a dataset ID,
+// edition and version.
+type Event struct {
+	Type       Type   `json:"type"`
+	CodeListID string `json:"code_list_id,omitempty"`
+	DatasetID  string `json:"dataset_id,omitempty"`
+	Edition    string `json:"edition,omitempty"`
+	Version    string `json:"version,omitempty"`
+}
+
+// Handler handles a change event
+type Handler func(ctx context.Context, event Event) error
+
+// Consumer delivers change events to a handler from when it is started until it is stopped
+type Consumer interface {
+	Start(ctx context.Context, handle Handler)
+	Stop(ctx context.Context) error
+	Checker(ctx context.Context, state *health.CheckState) error
+}
+
+// Validate returns an error if the event has an unknown type, or is missing a field its type needs
+func (e Event) Validate() error {
+	switch e.Type {
+	case CodeListPublished:
+		if e.CodeListID == "" {
+			return fmt.Errorf("%s event has no code list id", e.Type)
+		}
+	case EditionPublished:
+		if e.CodeListID == "" || e.Edition == "" {
+			return fmt.Errorf("%s event has no code list id or edition", e.Type)
+		}
+	case DatasetVersionPublished:
+		if e.DatasetID == "" {
+			return fmt.Errorf("%s event has no dataset id", e.Type)
+		}
+	case "":
+		return errors.New("event has no type")
+	default:
+		return fmt.Errorf("unknown event type %q", e.Type)
+	}
+	return nil
+}
+
+// Decode decodes and validates an event from its JSON
+func Decode(b []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(b, &event); err != nil {
+		return Event{}, err
+	}
+	return event, event.Validate()
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDecode(t *testing.T) {
+
+	Convey("Given change events as JSON", t, func() {
+
+		Convey("a valid event of each type is decoded", func() {
+			event, err := Decode([]byte(`{"type":"code-list-published","code_list_id":"local-authority"}`))
+			So(err, ShouldBeNil)
+			So(event, ShouldResemble, Event{Type: CodeListPublished, CodeListID: "local-authority"})
+
+			event, err = Decode([]byte(`{"type":"edition-published","code_list_id":"local-authority","edition":"2019"}`))
+			So(err, ShouldBeNil)
+			So(event.Edition, ShouldEqual, "2019")
+
+			event, err = Decode([]byte(`{"type":"dataset-version-published","dataset_id":"cpih01","edition":"time-series","version":"5"}`))
+			So(err, ShouldBeNil)
+			So(event.DatasetID, ShouldEqual, "cpih01")
+		})
+
+		Convey("malformed events, unknown types and events missing the fields their type needs are rejected", func() {
+			for _, b := range []string{
+				`not json`,
+				`{}`,
+				`{"type":"code-list-deleted","code_list_id":"local-authority"}`,
+				`{"type":"code-list-published"}`,
+				`{"type":"edition-published","code_list_id":"local-authority"}`,
+				`{"type":"dataset-version-published","edition":"time-series"}`,
+			} {
+				_, err := Decode([]byte(b))
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a started in-memory consumer", t, func() {
+		var handled []Event
+		m := NewMemory(2)
+		m.Start(ctx, func(ctx context.Context, event Event) error {
+			handled = append(handled, event)
+			if event.CodeListID == "fails" {
+				return errors.New("handler failed")
+			}
+			return nil
+		})
+
+		Convey("published events are handled in order, whether or not handling them fails", func() {
+			first := Event{Type: CodeListPublished, CodeListID: "fails"}
+			second := Event{Type: CodeListPublished, CodeListID: "local-authority"}
+			So(m.Publish(ctx, first), ShouldBeNil)
+			So(m.Publish(ctx, second), ShouldBeNil)
+			So(<-m.Handled(), ShouldResemble, first)
+			So(<-m.Handled(), ShouldResemble, second)
+			So(handled, ShouldResemble, []Event{first, second})
+			So(m.Stop(ctx), ShouldBeNil)
+		})
+
+		Convey("events cannot be published once it is stopped", func() {
+			So(m.Stop(ctx), ShouldBeNil)
+			So(m.Stop(ctx), ShouldBeNil)
+			So(m.Publish(ctx, Event{Type: CodeListPublished, CodeListID: "local-authority"}), ShouldEqual, ErrStopped)
+		})
+	})
+}
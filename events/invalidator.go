@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/cache"
+	"github.com/ONSdigital/dp-frontend-geography-controller/pagecache"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Invalidator purges the cache entries and pages that a change event makes out of date
+type Invalidator struct {
+	codeLists *cache.CodeListClient
+	pages     *pagecache.Cache
+}
+
+// NewInvalidator creates an Invalidator of the provided caches
+func NewInvalidator(codeLists *cache.CodeListClient, pages *pagecache.Cache) *Invalidator {
+	return &Invalidator{
+		codeLists: codeLists,
+		pages:     pages,
+	}
+}
+
+// Handle purges the entries for the code list, edition or dataset that the event is for. A published dataset version
+// changes the latest version that area pages link to, so the datasets related to each code that the dataset is
+// related to are purged, along with the area pages for those codes.
+func (i *Invalidator) Handle(ctx context.Context, event Event) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+
+	codeLists, pages := 0, 0
+	switch event.Type {
+	case CodeListPublished:
+		codeLists = i.codeLists.PurgeCodeList(event.CodeListID)
+		pages = i.pages.PurgeCodeList(event.CodeListID)
+	case EditionPublished:
+		codeLists = i.codeLists.PurgeEdition(event.CodeListID, event.Edition)
+		pages = i.pages.PurgeCodeList(event.CodeListID)
+	case DatasetVersionPublished:
+		codes := i.codeLists.PurgeDataset(event.DatasetID)
+		codeLists = len(codes)
+		for _, code := range codes {
+			pages += i.pages.PurgeArea(code.CodeListID, code.ID)
+		}
+	}
+
+	log.Info(ctx, "invalidated caches for change event", log.Data{"event": event, "code_list_cache": codeLists, "page_cache": pages})
+	return nil
+}
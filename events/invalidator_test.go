@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/cache"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-geography-controller/pagecache"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInvalidator(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given populated code list and page caches", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+
+		mockClient := &handlers.CodeListClientMock{
+			GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+				return codelist.CodeListResults{}, nil
+			},
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				return codelist.EditionsListResults{}, nil
+			},
+			GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				return codelist.CodesResults{}, nil
+			},
+			GetDatasetsByCodeFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+				datasetID := "cpih01"
+				if codeID == "E06000002" {
+					datasetID = "mid-year-pop-est"
+				}
+				return codelist.DatasetsResult{Datasets: []codelist.Dataset{{Links: codelist.DatasetLinks{Self: codelist.Link{ID: datasetID}}}}}, nil
+			},
+		}
+		codeLists := cache.NewCodeListClient(mockClient, cfg)
+		codeLists.GetGeographyCodeLists(ctx, "", "")
+		codeLists.GetCodeListEditions(ctx, "", "", "local-authority")
+		codeLists.GetCodeListEditions(ctx, "", "", "countries")
+		codeLists.GetCodes(ctx, "", "", "local-authority", "2019")
+		codeLists.GetCodes(ctx, "", "", "local-authority", "2018")
+		codeLists.GetDatasetsByCode(ctx, "", "", "local-authority", "2019", "E06000001")
+		codeLists.GetDatasetsByCode(ctx, "", "", "local-authority", "2019", "E06000002")
+
+		pages := pagecache.New(time.Minute, 1<<20)
+		page := pages.Middleware(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html></html>"))
+		})
+		for _, path := range []string{"/geography", "/geography/local-authority", "/geography/local-authority/E06000001", "/geography/local-authority/E06000002", "/geography/countries"} {
+			page(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+
+		i := NewInvalidator(codeLists, pages)
+
+		Convey("a published code list purges its entries, the list of code lists and the pages that show it", func() {
+			So(i.Handle(ctx, Event{Type: CodeListPublished, CodeListID: "local-authority"}), ShouldBeNil)
+			keys := codeLists.Keys()
+			So(keys["code_lists"], ShouldBeEmpty)
+			So(keys["editions"], ShouldResemble, []string{"/code-lists/countries/editions"})
+			So(keys["codes"], ShouldBeEmpty)
+			So(keys["datasets"], ShouldBeEmpty)
+			So(pages.Keys(), ShouldHaveLength, 1)
+		})
+
+		Convey("a published edition purges its entries and the code list's list of editions", func() {
+			So(i.Handle(ctx, Event{Type: EditionPublished, CodeListID: "local-authority", Edition: "2018"}), ShouldBeNil)
+			keys := codeLists.Keys()
+			So(keys["code_lists"], ShouldHaveLength, 1)
+			So(keys["editions"], ShouldResemble, []string{"/code-lists/countries/editions"})
+			So(keys["codes"], ShouldResemble, []string{"/code-lists/local-authority/editions/2019/codes"})
+			So(keys["datasets"], ShouldHaveLength, 2)
+			So(pages.Keys(), ShouldHaveLength, 1)
+		})
+
+		Convey("a published dataset version purges the datasets and area pages of the codes it is related to", func() {
+			So(i.Handle(ctx, Event{Type: DatasetVersionPublished, DatasetID: "cpih01", Edition: "time-series", Version: "5"}), ShouldBeNil)
+			So(codeLists.Keys()["datasets"], ShouldResemble, []string{"/code-lists/local-authority/editions/2019/codes/E06000002/datasets"})
+			So(pages.Keys(), ShouldHaveLength, 4)
+			for _, key := range pages.Keys() {
+				So(key, ShouldNotStartWith, "/geography/local-authority/E06000001 ")
+			}
+		})
+
+		Convey("an invalid event is rejected without purging anything", func() {
+			So(i.Handle(ctx, Event{Type: CodeListPublished}), ShouldNotBeNil)
+			So(pages.Keys(), ShouldHaveLength, 5)
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/segmentio/kafka-go"
+)
+
+// fetchRetryInterval is how long to wait before fetching again after failing to fetch a message
+var fetchRetryInterval = time.Second
+
+// Reader is the part of a kafka-go Reader that the Kafka consumer uses, so that it can be run against a stand-in
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Kafka is a Consumer of JSON events from a Kafka topic. Each message is committed once it has been handled, even if
+// it was malformed or handling it failed, as the caches will expire the entries it was for regardless.
+type Kafka struct {
+	reader Reader
+	topic  string
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mutex  sync.Mutex
+	err    error
+}
+
+// NewKafka creates a Kafka consumer of topic, reading as a member of the consumer group from the brokers
+func NewKafka(brokers []string, topic, group string) *Kafka {
+	return NewKafkaWithReader(kafka.NewReader(kafka.ReaderConfig{
+		Brokers: brokers,
+		Topic:   topic,
+		GroupID: group,
+	}), topic)
+}
+
+// NewKafkaWithReader creates a Kafka consumer of topic that fetches messages from reader
+func NewKafkaWithReader(reader Reader, topic string) *Kafka {
+	return &Kafka{
+		reader: reader,
+		topic:  topic,
+	}
+}
+
+// Start handles events from the topic with handle until the consumer is stopped
+func (k *Kafka) Start(ctx context.Context, handle Handler) {
+	ctx, k.cancel = context.WithCancel(ctx)
+
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+		for {
+			msg, err := k.reader.FetchMessage(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Error(ctx, "error fetching change event", err, log.Data{"topic": k.topic})
+				k.setErr(err)
+				select {
+				case <-time.After(fetchRetryInterval):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			k.handle(ctx, msg, handle)
+			if err := k.reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+				log.Error(ctx, "error committing change event", err, log.Data{"topic": k.topic, "offset": msg.Offset})
+				k.setErr(err)
+				continue
+			}
+			k.setErr(nil)
+		}
+	}()
+}
+
+func (k *Kafka) handle(ctx context.Context, msg kafka.Message, handle Handler) {
+	logData := log.Data{"topic": k.topic, "partition": msg.Partition, "offset": msg.Offset}
+
+	event, err := Decode(msg.Value)
+	if err != nil {
+		log.Warn(ctx, "skipping malformed change event", logData, log.FormatErrors([]error{err}))
+		return
+	}
+	logData["event"] = event
+	if err := handle(ctx, event); err != nil {
+		log.Error(ctx, "error handling change event", err, logData)
+	}
+}
+
+// Stop stops fetching events, waiting for the event being handled, if any, and closes the reader
+func (k *Kafka) Stop(ctx context.Context) error {
+	if k.cancel != nil {
+		k.cancel()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		k.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return k.reader.Close()
+}
+
+// Checker reports a warning while events cannot be fetched or committed. It is not critical, as the caches still
+// expire entries without events.
+func (k *Kafka) Checker(ctx context.Context, state *health.CheckState) error {
+	k.mutex.Lock()
+	err := k.err
+	k.mutex.Unlock()
+
+	if err != nil {
+		return state.Update(health.StatusWarning, fmt.Sprintf("error consuming change events from %s: %s", k.topic, err), 0)
+	}
+	return state.Update(health.StatusOK, fmt.Sprintf("consuming change events from %s", k.topic), 0)
+}
+
+func (k *Kafka) setErr(err error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.err = err
+}
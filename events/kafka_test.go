@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/segmentio/kafka-go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// standInReader is a local stand-in for a kafka-go Reader, which delivers the messages sent to it and records the
+// messages committed
+type standInReader struct {
+	messages  chan kafka.Message
+	errs      chan error
+	mutex     sync.Mutex
+	committed []kafka.Message
+	closed    bool
+}
+
+func newStandInReader() *standInReader {
+	return &standInReader{
+		messages: make(chan kafka.Message),
+		errs:     make(chan error),
+	}
+}
+
+func (r *standInReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case msg := <-r.messages:
+		return msg, nil
+	case err := <-r.errs:
+		return kafka.Message{}, err
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *standInReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+func (r *standInReader) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *standInReader) committedOffsets() []int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var offsets []int64
+	for _, msg := range r.committed {
+		offsets = append(offsets, msg.Offset)
+	}
+	return offsets
+}
+
+func TestKafka(t *testing.T) {
+	ctx := context.Background()
+	fetchRetryInterval = time.Millisecond
+
+	Convey("Given a Kafka consumer started against a stand-in reader", t, func() {
+		reader := newStandInReader()
+		k := NewKafkaWithReader(reader, "geography-change-events")
+
+		handled := make(chan Event, 10)
+		k.Start(ctx, func(ctx context.Context, event Event) error {
+			handled <- event
+			if event.CodeListID == "fails" {
+				return errors.New("handler failed")
+			}
+			return nil
+		})
+
+		Convey("each event is handled and then committed", func() {
+			reader.messages <- kafka.Message{Offset: 1, Value: []byte(`{"type":"code-list-published","code_list_id":"local-authority"}`)}
+			reader.messages <- kafka.Message{Offset: 2, Value: []byte(`{"type":"edition-published","code_list_id":"local-authority","edition":"2019"}`)}
+			So(<-handled, ShouldResemble, Event{Type: CodeListPublished, CodeListID: "local-authority"})
+			So(<-handled, ShouldResemble, Event{Type: EditionPublished, CodeListID: "local-authority", Edition: "2019"})
+
+			So(k.Stop(ctx), ShouldBeNil)
+			So(reader.committedOffsets(), ShouldResemble, []int64{1, 2})
+			So(reader.closed, ShouldBeTrue)
+		})
+
+		Convey("malformed events and events that fail to be handled are committed so they are not retried", func() {
+			reader.messages <- kafka.Message{Offset: 1, Value: []byte(`not json`)}
+			reader.messages <- kafka.Message{Offset: 2, Value: []byte(`{"type":"code-list-published","code_list_id":"fails"}`)}
+			reader.messages <- kafka.Message{Offset: 3, Value: []byte(`{"type":"code-list-published","code_list_id":"local-authority"}`)}
+			So((<-handled).CodeListID, ShouldEqual, "fails")
+			So((<-handled).CodeListID, ShouldEqual, "local-authority")
+
+			So(k.Stop(ctx), ShouldBeNil)
+			So(reader.committedOffsets(), ShouldResemble, []int64{1, 2, 3})
+		})
+
+		Convey("the checker warns while messages cannot be fetched, and recovers once they can", func() {
+			state := health.NewCheckState("change event consumer")
+			So(k.Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, health.StatusOK)
+
+			// the second error is only fetched once the first has been recorded
+			reader.errs <- errors.New("broker unavailable")
+			reader.errs <- errors.New("broker unavailable")
+			So(k.Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, health.StatusWarning)
+
+			reader.messages <- kafka.Message{Offset: 2, Value: []byte(`{"type":"code-list-published","code_list_id":"local-authority"}`)}
+			<-handled
+			So(k.Stop(ctx), ShouldBeNil)
+			So(k.Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, health.StatusOK)
+		})
+	})
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// ErrStopped is returned when publishing to a consumer that has been stopped
+var ErrStopped = errors.New("event consumer stopped")
+
+// Memory is a Consumer of events published to it in the same process, for tests and local development. Events are
+// handled one at a time, in the order they were published.
+type Memory struct {
+	events  chan Event
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+	handled chan Event
+}
+
+// NewMemory creates a Memory consumer that buffers up to size events published before they are handled
+func NewMemory(size int) *Memory {
+	return &Memory{
+		events:  make(chan Event, size),
+		done:    make(chan struct{}),
+		handled: make(chan Event, size),
+	}
+}
+
+// Publish queues an event to be handled, blocking while the buffer is full
+func (m *Memory) Publish(ctx context.Context, event Event) error {
+	select {
+	case <-m.done:
+		return ErrStopped
+	default:
+	}
+
+	select {
+	case m.events <- event:
+		return nil
+	case <-m.done:
+		return ErrStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Handled returns a channel that receives each event once it has been handled, whether or not handling it failed.
+// Events are dropped from it while its buffer is full.
+func (m *Memory) Handled() <-chan Event {
+	return m.handled
+}
+
+// Start handles published events with handle until the consumer is stopped
+func (m *Memory) Start(ctx context.Context, handle Handler) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for {
+			select {
+			case event := <-m.events:
+				if err := handle(ctx, event); err != nil {
+					log.Error(ctx, "error handling change event", err, log.Data{"event": event})
+				}
+				select {
+				case m.handled <- event:
+				default:
+				}
+			case <-m.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops handling events, waiting for the event being handled, if any. Events still queued are dropped.
+func (m *Memory) Stop(ctx context.Context) error {
+	m.once.Do(func() { close(m.done) })
+
+	stopped := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Checker always reports OK, as events published in the same process cannot fail to be received
+func (m *Memory) Checker(ctx context.Context, state *health.CheckState) error {
+	return state.Update(health.StatusOK, "in-memory event consumer is running", 0)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	dphandlers "github.com/ONSdigital/dp-net/handlers"
+	dprequest "github.com/ONSdigital/dp-net/request"
+)
+
+// Cache-Control values for responses that must not be stored by shared caches
+const (
+	cacheControlPreview = "private, no-store"
+	cacheControlError   = "no-store"
+)
+
+// vary lists the request headers that change a response: Accept chooses between HTML and JSON, Cookie carries the
+// language and cookie preferences that the page is rendered with, and the Florence token and collection ID make the
+// request a preview. The language also depends on the host, which is already part of every cache key.
+var vary = strings.Join([]string{"Accept", "Cookie", dprequest.FlorenceHeaderKey, dprequest.CollectionIDHeaderKey}, ", ")
+
+// CacheControl wraps a route's handler so that its responses say how they may be cached. Successful public
+// responses can be cached according to policy, previews must not be stored at all, as they are specific to a
+// Florence user or collection, and error responses must not be stored either, so that a transient failure is not
+// served from a cache. The same goes for successful responses that the handler has marked with preventCaching.
+func CacheControl(policy config.CachePolicy, h http.HandlerFunc) http.HandlerFunc {
+	public := publicCacheControl(policy)
+	return func(w http.ResponseWriter, req *http.Request) {
+		cacheControl := public
+		if IsPreviewRequest(req) {
+			cacheControl = cacheControlPreview
+		}
+		w.Header().Set("Vary", vary)
+		h(&cacheControlWriter{ResponseWriter: w, cacheControl: cacheControl}, req)
+	}
+}
+
+// preventCaching marks a successful response as one that must not be stored, such as a page rendered without some
+// of its content because downstream calls failed, so that it is not served once the downstream service has recovered
+func preventCaching(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", cacheControlError)
+}
+
+// publicCacheControl returns the Cache-Control value for the successful public responses of a route
+func publicCacheControl(policy config.CachePolicy) string {
+	directives := []string{"public", fmt.Sprintf("max-age=%d", int(policy.MaxAge.Seconds()))}
+	if policy.SMaxAge > 0 {
+		directives = append(directives, fmt.Sprintf("s-maxage=%d", int(policy.SMaxAge.Seconds())))
+	}
+	if policy.StaleWhileRevalidate > 0 {
+		directives = append(directives, fmt.Sprintf("stale-while-revalidate=%d", int(policy.StaleWhileRevalidate.Seconds())))
+	}
+	return strings.Join(directives, ", ")
+}
+
+// IsPreviewRequest returns true if the request carries a Florence user token or a collection ID, in the same way
+// as the handlers are given them
+func IsPreviewRequest(req *http.Request) bool {
+	if collectionID, err := dprequest.GetCollectionID(req); err == nil && collectionID != "" {
+		return true
+	}
+	token, err := dphandlers.GetFlorenceToken(req.Context(), req)
+	return err == nil && token != ""
+}
+
+// cacheControlWriter sets the Cache-Control header when the response status is written, as errors are not known
+// about until the handler has made its downstream calls
+type cacheControlWriter struct {
+	http.ResponseWriter
+	cacheControl string
+	wroteHeader  bool
+}
+
+func (w *cacheControlWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		cacheControl := w.cacheControl
+		failed := status != http.StatusOK && status != http.StatusNotModified
+		if (failed || w.Header().Get("Cache-Control") == cacheControlError) && cacheControl != cacheControlPreview {
+			cacheControl = cacheControlError
+		}
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer, if it can be flushed, so that streamed responses such as CSV downloads still
+// reach the client as they are written
+func (w *cacheControlWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
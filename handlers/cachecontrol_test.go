@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCacheControl(t *testing.T) {
+	policy := config.CachePolicy{MaxAge: 5 * time.Minute, SMaxAge: 15 * time.Minute, StaleWhileRevalidate: time.Minute}
+
+	Convey("Given a route wrapped with a cache policy", t, func() {
+		status := http.StatusOK
+		h := CacheControl(policy, func(w http.ResponseWriter, req *http.Request) {
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+			}
+			w.Write([]byte("page"))
+		})
+		req := httptest.NewRequest("GET", "/geography", nil)
+		w := httptest.NewRecorder()
+
+		Convey("successful public responses can be cached according to the policy", func() {
+			h(w, req)
+			So(w.Header().Get("Cache-Control"), ShouldEqual, "public, max-age=300, s-maxage=900, stale-while-revalidate=60")
+			So(w.Header().Get("Vary"), ShouldEqual, "Accept, Cookie, X-Florence-Token, Collection-Id")
+			So(w.Body.String(), ShouldEqual, "page")
+		})
+
+		Convey("not modified responses have the same policy as the page", func() {
+			status = http.StatusNotModified
+			h(w, req)
+			So(w.Header().Get("Cache-Control"), ShouldEqual, "public, max-age=300, s-maxage=900, stale-while-revalidate=60")
+		})
+
+		Convey("public error responses must not be stored", func() {
+			status = http.StatusServiceUnavailable
+			h(w, req)
+			So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+			So(w.Header().Get("Cache-Control"), ShouldEqual, "no-store")
+		})
+
+		Convey("successful responses that the handler prevents caching of must not be stored", func() {
+			h = CacheControl(policy, func(w http.ResponseWriter, req *http.Request) {
+				preventCaching(w)
+				w.Write([]byte("partial page"))
+			})
+			h(w, req)
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Cache-Control"), ShouldEqual, "no-store")
+		})
+
+		Convey("responses to requests with a Florence token are private and must not be stored", func() {
+			req.Header.Set("X-Florence-Token", "florence-token")
+			h(w, req)
+			So(w.Header().Get("Cache-Control"), ShouldEqual, "private, no-store")
+		})
+
+		Convey("responses to requests for a collection are private and must not be stored, even on error", func() {
+			req.AddCookie(&http.Cookie{Name: "collection", Value: "my-collection"})
+			status = http.StatusNotFound
+			h(w, req)
+			So(w.Header().Get("Cache-Control"), ShouldEqual, "private, no-store")
+		})
+
+		Convey("streamed responses are still flushed to the client", func() {
+			h = CacheControl(policy, func(w http.ResponseWriter, req *http.Request) {
+				w.(http.Flusher).Flush()
+			})
+			h(w, req)
+			So(w.Flushed, ShouldBeTrue)
+			So(w.Header().Get("Cache-Control"), ShouldStartWith, "public")
+		})
+	})
+
+	Convey("directives that are not set are left out of the policy", t, func() {
+		So(publicCacheControl(config.CachePolicy{MaxAge: time.Minute}), ShouldEqual, "public, max-age=60")
+	})
+}
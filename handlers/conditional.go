@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// pageETag returns a strong entity tag for a page, computed from its model before it is rendered. The template and
+// media type are part of the tag, as the same model gives a different representation with each of them.
+func pageETag(templateName, mediaType string, pageJSON []byte) string {
+	h := sha256.New()
+	h.Write([]byte(templateName))
+	h.Write([]byte{0})
+	h.Write([]byte(mediaType))
+	h.Write([]byte{0})
+	h.Write(pageJSON)
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// notModified returns true if the request's If-None-Match says the client already has the page. It is compared
+// weakly against etag. The pages have no Last-Modified time that covers all of their content, so If-Modified-Since is
+// not used.
+func notModified(req *http.Request, etag string) bool {
+	for _, tag := range strings.Split(req.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && (tag == "*" || strings.TrimPrefix(tag, "W/") == etag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPageETag(t *testing.T) {
+	Convey("pageETag is a strong tag that changes with the page model, template and media type", t, func() {
+		etag := pageETag("geography-area", mediaTypeHTML, []byte(`{"type":"area"}`))
+		So(etag, ShouldStartWith, `"`)
+		So(etag, ShouldEndWith, `"`)
+		So(pageETag("geography-area", mediaTypeHTML, []byte(`{"type":"area"}`)), ShouldEqual, etag)
+		So(pageETag("geography-area", mediaTypeHTML, []byte(`{"type":"list"}`)), ShouldNotEqual, etag)
+		So(pageETag("geography-list", mediaTypeHTML, []byte(`{"type":"area"}`)), ShouldNotEqual, etag)
+		So(pageETag("geography-area", mediaTypeJSON, []byte(`{"type":"area"}`)), ShouldNotEqual, etag)
+	})
+}
+
+func TestNotModified(t *testing.T) {
+	etag := `"abc"`
+
+	Convey("Given a request for a page", t, func() {
+		req := httptest.NewRequest("GET", "/geography", nil)
+
+		Convey("it is modified if the request has no preconditions", func() {
+			So(notModified(req, etag), ShouldBeFalse)
+		})
+
+		Convey("If-None-Match matches the current tag, a weak version of it, any tag in a list, or *", func() {
+			for _, inm := range []string{`"abc"`, `W/"abc"`, `"xyz", "abc"`, `*`} {
+				req.Header.Set("If-None-Match", inm)
+				So(notModified(req, etag), ShouldBeTrue)
+			}
+			req.Header.Set("If-None-Match", `"xyz"`)
+			So(notModified(req, etag), ShouldBeFalse)
+		})
+
+		Convey("If-Modified-Since is ignored, as the pages have no last modified time", func() {
+			req.Header.Set("If-Modified-Since", "Mon, 01 Jun 2020 09:30:00 GMT")
+			So(notModified(req, etag), ShouldBeFalse)
+		})
+	})
+}
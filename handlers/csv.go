@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	dphandlers "github.com/ONSdigital/dp-net/handlers"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// csvFlushInterval is the number of rows written between each flush of the CSV to the client
+const csvFlushInterval = 1000
+
+// ListCSVDownload writes every code of the requested edition of a code-list, or the first edition if none was
+// requested, as a CSV file
+func ListCSVDownload(rend RenderClient, cli CodeListClient) http.HandlerFunc {
+	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, collectionID, userAuthToken string) {
+		ctx := withCollectionID(req.Context(), collectionID)
+		codeListID := mux.Vars(req)["codeListID"]
+		logData := log.Data{
+			"code_list_id": codeListID,
+		}
+		serviceAuthToken := getServiceAuthToken(req)
+		requestedEdition := getRequestedEdition(req)
+
+		codeListEditions, err := cli.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
+		if err != nil {
+			writeErrorPage(w, req, rend, lang, err, logData)
+			return
+		}
+
+		edition, err := selectEdition(codeListEditions.Items, requestedEdition)
+		if err == nil && edition == nil {
+			err = notFoundError{message: fmt.Sprintf("code-list %s has no editions", codeListID)}
+		}
+		if err != nil {
+			logData["edition"] = requestedEdition
+			writeErrorPage(w, req, rend, lang, err, logData)
+			return
+		}
+
+		logData["edition"] = edition.Edition
+		codes, err := cli.GetCodes(ctx, userAuthToken, serviceAuthToken, codeListID, edition.Edition)
+		if err != nil {
+			writeErrorPage(w, req, rend, lang, err, logData)
+			return
+		}
+
+		writeCodesCSV(ctx, w, codeListID, edition.Edition, codes.Items)
+	})
+}
+
+// writeCodesCSV writes the codes of an edition of a code-list to the client as CSV, sorted by label in the same
+// order as the list page. The codes have already been requested in full, so only the output is streamed: rows are
+// flushed to the client in batches as they are written, rather than the whole file being built before it is sent.
+func writeCodesCSV(ctx context.Context, w http.ResponseWriter, codeListID, edition string, codes []codelist.Item) {
+	sorted := make([]*codelist.Item, len(codes))
+	for i := range codes {
+		sorted[i] = &codes[i]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Label < sorted[j].Label
+	})
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.csv", codeListID, edition)))
+
+	flusher, _ := w.(http.Flusher)
+	csvWriter := csv.NewWriter(w)
+	rows := [][]string{{"code", "label", "edition"}}
+	for _, item := range sorted {
+		rows = append(rows, []string{escapeCSVFormula(item.Code), escapeCSVFormula(item.Label), edition})
+		if len(rows) < csvFlushInterval {
+			continue
+		}
+		if err := writeCSVRows(csvWriter, flusher, rows); err != nil {
+			log.Error(ctx, "error writing codes CSV", err, log.Data{"code_list_id": codeListID, "edition": edition})
+			return
+		}
+		rows = rows[:0]
+	}
+
+	if err := writeCSVRows(csvWriter, flusher, rows); err != nil {
+		log.Error(ctx, "error writing codes CSV", err, log.Data{"code_list_id": codeListID, "edition": edition})
+	}
+}
+
+// escapeCSVFormula prefixes a value that a spreadsheet would treat as a formula with a single quote, so that opening
+// the CSV in a spreadsheet shows the value rather than evaluating it
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func writeCSVRows(csvWriter *csv.Writer, flusher http.Flusher, rows [][]string) error {
+	if err := csvWriter.WriteAll(rows); err != nil {
+		return err
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestListCSVDownload(t *testing.T) {
+
+	Convey("test codes CSV download", t, func() {
+		w := httptest.NewRecorder()
+		router := mux.NewRouter()
+		mockCodeListClient := &CodeListClientMock{
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				return testEditions, nil
+			},
+			GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				return codelist.CodesResults{
+					Items: []codelist.Item{
+						{Code: "E06000028", Label: "Bournemouth"},
+						{Code: "S12000033", Label: "Aberdeen City"},
+						{Code: "E06000001", Label: "Hartlepool, Durham"},
+					},
+					Count: 3,
+				}, nil
+			},
+		}
+		expectedCSV := "code,label,edition\n" +
+			"S12000033,Aberdeen City,2017\n" +
+			"E06000028,Bournemouth,2017\n" +
+			"E06000001,\"Hartlepool, Durham\",2017\n"
+
+		Convey("the csv route writes every code of the requested edition sorted by label", func() {
+			req := httptest.NewRequest("GET", "/geography/local-authority.csv?edition=2017", nil)
+			router.Path("/geography/{codeListID}.csv").HandlerFunc(ListCSVDownload(&RenderClientMock{}, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "text/csv; charset=utf-8")
+			So(w.Header().Get("Content-Disposition"), ShouldEqual, `attachment; filename="local-authority-2017.csv"`)
+			So(w.Body.String(), ShouldEqual, expectedCSV)
+
+			getCodesCalls := mockCodeListClient.GetCodesCalls()
+			So(getCodesCalls, ShouldHaveLength, 1)
+			So(getCodesCalls[0].CodeListID, ShouldEqual, "local-authority")
+			So(getCodesCalls[0].Edition, ShouldEqual, "2017")
+		})
+
+		Convey("values that a spreadsheet would evaluate as formulas are escaped", func() {
+			mockCodeListClient.GetCodesFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				return codelist.CodesResults{
+					Items: []codelist.Item{
+						{Code: "E06000001", Label: "=HYPERLINK(\"http://example.com\")"},
+						{Code: "E06000002", Label: "+44"},
+						{Code: "E06000003", Label: "-1"},
+						{Code: "@SUM(A1)", Label: "Sum"},
+						{Code: "E06000004", Label: "Middlesbrough"},
+					},
+					Count: 5,
+				}, nil
+			}
+			req := httptest.NewRequest("GET", "/geography/local-authority.csv?edition=2017", nil)
+			router.Path("/geography/{codeListID}.csv").HandlerFunc(ListCSVDownload(&RenderClientMock{}, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldEqual, "code,label,edition\n"+
+				"E06000002,'+44,2017\n"+
+				"E06000003,'-1,2017\n"+
+				"E06000001,\"'=HYPERLINK(\"\"http://example.com\"\")\",2017\n"+
+				"E06000004,Middlesbrough,2017\n"+
+				"'@SUM(A1),Sum,2017\n")
+		})
+
+		Convey("the list route writes the same CSV when the request accepts text/csv", func() {
+			req := httptest.NewRequest("GET", "/geography/local-authority?edition=2017&page=2&limit=1", nil)
+			req.Header.Set("Accept", "text/csv")
+			mockRenderClient := &RenderClientMock{}
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldEqual, expectedCSV)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 0)
+		})
+
+		Convey("return a 404 status if the code-list has no editions", func() {
+			mockCodeListClient.GetCodeListEditionsFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				return codelist.EditionsListResults{}, nil
+			}
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			req := httptest.NewRequest("GET", "/geography/local-authority.csv", nil)
+			router.Path("/geography/{codeListID}.csv").HandlerFunc(ListCSVDownload(mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, http.StatusNotFound)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
+			So(mockCodeListClient.GetCodesCalls(), ShouldBeNil)
+		})
+	})
+}
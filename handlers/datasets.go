@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/models"
+	"github.com/ONSdigital/dp-frontend-models/model/geography/area"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// datasetEntry is a dataset related to a code that has passed validation, with the edition and website path that
+// the area page links to, and every edition of the dataset that links to a latest version
+type datasetEntry struct {
+	datasetID string
+	edition   string
+	path      string
+	editions  []models.DatasetEdition
+}
+
+// validateDatasetEntries validates the datasets related to a code, as returned by the code list API. Entries without
+// a dataset ID or without any edition that links to a latest version are skipped and logged, along with every
+// edition they do have. The valid entries are returned with the number of entries skipped.
+func validateDatasetEntries(ctx context.Context, datasets []codelist.Dataset, apiRouterVersion string, logData log.Data) ([]datasetEntry, int) {
+	var entries []datasetEntry
+	skipped := 0
+
+	for _, dataset := range datasets {
+		entry, err := validateDatasetEntry(dataset, apiRouterVersion)
+		if err != nil {
+			skipped++
+			log.Warn(ctx, "skipping malformed dataset related to code", log.Data{
+				"dataset_id": dataset.Links.Self.ID,
+				"editions":   dataset.Editions,
+				"reason":     err.Error(),
+			})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if skipped > 0 {
+		data := log.Data{"skipped": skipped, "total": len(datasets)}
+		for k, v := range logData {
+			data[k] = v
+		}
+		log.Warn(ctx, "skipped malformed datasets related to code", data)
+	}
+
+	return entries, skipped
+}
+
+// validateDatasetEntry returns the entry for dataset, linking to the first of its editions that has a valid latest
+// version link. Editions without a valid latest version link are left out of the entry's editions.
+func validateDatasetEntry(dataset codelist.Dataset, apiRouterVersion string) (datasetEntry, error) {
+	if dataset.Links.Self.ID == "" {
+		return datasetEntry{}, errors.New("missing dataset id")
+	}
+	if len(dataset.Editions) == 0 {
+		return datasetEntry{}, errors.New("no editions")
+	}
+
+	entry := datasetEntry{datasetID: dataset.Links.Self.ID}
+	for _, edition := range dataset.Editions {
+		if edition.Links.LatestVersion.Href == "" {
+			continue
+		}
+		latestVersionURL, err := url.Parse(edition.Links.LatestVersion.Href)
+		if err != nil {
+			continue
+		}
+		entry.editions = append(entry.editions, models.DatasetEdition{
+			Edition:       edition.Links.Self.ID,
+			LatestVersion: edition.Links.LatestVersion.ID,
+			URI:           strings.TrimPrefix(latestVersionURL.Path, apiRouterVersion),
+		})
+	}
+	if len(entry.editions) == 0 {
+		return datasetEntry{}, errors.New("no edition with a valid latest version link")
+	}
+
+	entry.edition = entry.editions[0].Edition
+	entry.path = entry.editions[0].URI
+	return entry, nil
+}
+
+// getDatasets gets the details of each of the datasets related to a code, using the configured number of workers
+// and timeout for each call. The dataset API only has release dates on versions, so if withReleaseDate is set the
+// latest version of each linked edition is also requested. The datasets that could be retrieved are returned in the
+// order they were requested in, along with the first error that occurred, if any.
+func getDatasets(ctx context.Context, cfg config.Config, dcli DatasetClient, userAuthToken, serviceAuthToken, collectionID string, entries []datasetEntry, withReleaseDate bool) ([]models.Dataset, error) {
+	results := make([]*models.Dataset, len(entries))
+
+	fanOutCtx, span := startFanOut(ctx, "area_page", len(entries))
+	err := fanOut(fanOutCtx, len(entries), cfg.AreaPageWorkers, cfg.AreaPageCallTimeout, func(ctx context.Context, i int) error {
+		entry := entries[i]
+
+		datasetDetails, err := dcli.Get(ctx, userAuthToken, serviceAuthToken, collectionID, entry.datasetID)
+		if err != nil {
+			log.Error(ctx, "error getting dataset", err, log.Data{"dataset_id": entry.datasetID})
+			return err
+		}
+		result := &models.Dataset{
+			Dataset: area.Dataset{
+				ID:          entry.edition,
+				Label:       datasetDetails.Title,
+				Description: datasetDetails.Description,
+				URI:         entry.path,
+			},
+			DatasetID: entry.datasetID,
+			Editions:  entry.editions,
+		}
+
+		if withReleaseDate {
+			version, err := dcli.GetVersion(ctx, userAuthToken, serviceAuthToken, "", collectionID, entry.datasetID, entry.edition, entry.editions[0].LatestVersion)
+			if err != nil {
+				log.Warn(ctx, "error getting latest version of dataset, sorting it without a release date", log.Data{"dataset_id": entry.datasetID}, log.FormatErrors([]error{err}))
+			} else {
+				result.ReleaseDate = version.ReleaseDate
+			}
+		}
+
+		results[i] = result
+		return nil
+	})
+	EndSpan(span, err)
+
+	var datasets []models.Dataset
+	for _, dataset := range results {
+		if dataset != nil {
+			datasets = append(datasets, *dataset)
+		}
+	}
+	return datasets, err
+}
+
+// Orders that the datasets on the area page can be sorted in
+const (
+	sortTitle       = "title"
+	sortReleaseDate = "release_date"
+	sortID          = "id"
+)
+
+// getSort returns the order requested in the sort query parameter, falling back to sorting by title if no order, or
+// an unknown order, was requested
+func getSort(req *http.Request) string {
+	switch sort := req.URL.Query().Get("sort"); sort {
+	case sortTitle, sortReleaseDate, sortID:
+		return sort
+	default:
+		return sortTitle
+	}
+}
+
+// filterDatasets returns the datasets whose title or description contains every term in q, ignoring case
+func filterDatasets(datasets []models.Dataset, q string) []models.Dataset {
+	terms := strings.Fields(strings.ToLower(q))
+	if len(terms) == 0 {
+		return datasets
+	}
+
+	var filtered []models.Dataset
+	for _, dataset := range datasets {
+		text := strings.ToLower(dataset.Label + " " + dataset.Description)
+		matches := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, dataset)
+		}
+	}
+	return filtered
+}
+
+// sortDatasets sorts datasets by title, by dataset ID, or by release date with the most recent first. Datasets
+// without a valid release date come after those with one, and ties are broken by title and then dataset ID so the
+// order is the same on every request.
+func sortDatasets(datasets []models.Dataset, order string) {
+	byTitle := func(a, b models.Dataset) bool {
+		if a.Label != b.Label {
+			return a.Label < b.Label
+		}
+		return a.DatasetID < b.DatasetID
+	}
+
+	sort.SliceStable(datasets, func(i, j int) bool {
+		a, b := datasets[i], datasets[j]
+		switch order {
+		case sortID:
+			if a.DatasetID != b.DatasetID {
+				return a.DatasetID < b.DatasetID
+			}
+		case sortReleaseDate:
+			aDate, aErr := time.Parse(time.RFC3339, a.ReleaseDate)
+			bDate, bErr := time.Parse(time.RFC3339, b.ReleaseDate)
+			switch {
+			case aErr == nil && bErr == nil && !aDate.Equal(bDate):
+				return aDate.After(bDate)
+			case aErr == nil && bErr != nil:
+				return true
+			case aErr != nil && bErr == nil:
+				return false
+			}
+		}
+		return byTitle(a, b)
+	})
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/models"
+	"github.com/ONSdigital/dp-frontend-models/model/geography/area"
+	"github.com/ONSdigital/log.go/v2/log"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateDatasetEntries(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given the datasets related to a code, some of which are malformed", t, func() {
+		noEditions := testDatasetByCode("cpih01", "time-series")
+		noEditions.Editions = nil
+
+		noID := testDatasetByCode("", "time-series")
+
+		noLatestVersion := testDatasetByCode("ashe-table-7-earnings", "2019")
+		noLatestVersion.Editions[0].Links.LatestVersion.Href = ""
+
+		secondEditionValid := testDatasetByCode("mid-year-pop-est", "2020")
+		secondEditionValid.Editions = append([]codelist.DatasetEdition{{}}, secondEditionValid.Editions...)
+
+		datasets := []codelist.Dataset{
+			noEditions,
+			testDatasetByCode("ageing-population-estimates", "time-series"),
+			noID,
+			noLatestVersion,
+			secondEditionValid,
+		}
+
+		Convey("the malformed entries are skipped and counted", func() {
+			entries, skipped := validateDatasetEntries(ctx, datasets, "/v1", log.Data{})
+
+			So(skipped, ShouldEqual, 3)
+			So(entries, ShouldResemble, []datasetEntry{
+				{
+					datasetID: "ageing-population-estimates",
+					edition:   "time-series",
+					path:      "/datasets/ageing-population-estimates/editions/time-series/versions/1",
+					editions: []models.DatasetEdition{
+						{Edition: "time-series", LatestVersion: "1", URI: "/datasets/ageing-population-estimates/editions/time-series/versions/1"},
+					},
+				},
+				{
+					datasetID: "mid-year-pop-est",
+					edition:   "2020",
+					path:      "/datasets/mid-year-pop-est/editions/2020/versions/1",
+					editions: []models.DatasetEdition{
+						{Edition: "2020", LatestVersion: "1", URI: "/datasets/mid-year-pop-est/editions/2020/versions/1"},
+					},
+				},
+			})
+		})
+
+		Convey("every edition is listed, with the api router version stripped from the website paths", func() {
+			datasets[1].Editions = []codelist.DatasetEdition{
+				testDatasetEdition("ageing-population-estimates", "2021", "3", "http://localhost:23200/v1"),
+				testDatasetEdition("ageing-population-estimates", "2020", "2", "http://localhost:23200/v1"),
+				testDatasetEdition("ageing-population-estimates", "2019", "1", "http://localhost:23200/v1"),
+			}
+			entries, _ := validateDatasetEntries(ctx, datasets, "/v1", log.Data{})
+
+			So(entries[0].edition, ShouldEqual, "2021")
+			So(entries[0].path, ShouldEqual, "/datasets/ageing-population-estimates/editions/2021/versions/3")
+			So(entries[0].editions, ShouldResemble, []models.DatasetEdition{
+				{Edition: "2021", LatestVersion: "3", URI: "/datasets/ageing-population-estimates/editions/2021/versions/3"},
+				{Edition: "2020", LatestVersion: "2", URI: "/datasets/ageing-population-estimates/editions/2020/versions/2"},
+				{Edition: "2019", LatestVersion: "1", URI: "/datasets/ageing-population-estimates/editions/2019/versions/1"},
+			})
+		})
+	})
+}
+
+func testDataset(datasetID, title, description, releaseDate string) models.Dataset {
+	return models.Dataset{
+		Dataset:     area.Dataset{Label: title, Description: description},
+		DatasetID:   datasetID,
+		ReleaseDate: releaseDate,
+	}
+}
+
+func datasetIDs(datasets []models.Dataset) []string {
+	var ids []string
+	for _, dataset := range datasets {
+		ids = append(ids, dataset.DatasetID)
+	}
+	return ids
+}
+
+func TestSortAndFilterDatasets(t *testing.T) {
+	Convey("Given the datasets related to an area", t, func() {
+		datasets := []models.Dataset{
+			testDataset("mid-year-pop-est", "Population estimates", "Mid-year population estimates for the UK", "2021-06-25T00:00:00.000Z"),
+			testDataset("cpih01", "Consumer prices index", "Consumer prices including owner occupiers' housing costs", "2022-01-19T07:00:00.000Z"),
+			testDataset("ageing-population-estimates", "Ageing population estimates", "Population estimates by age group", ""),
+			testDataset("ashe-table-7-earnings", "Earnings", "Annual survey of hours and earnings", "2021-06-25T00:00:00.000Z"),
+		}
+
+		Convey("they are sorted by title", func() {
+			sortDatasets(datasets, sortTitle)
+			So(datasetIDs(datasets), ShouldResemble, []string{"ageing-population-estimates", "cpih01", "ashe-table-7-earnings", "mid-year-pop-est"})
+		})
+
+		Convey("they are sorted by dataset id", func() {
+			sortDatasets(datasets, sortID)
+			So(datasetIDs(datasets), ShouldResemble, []string{"ageing-population-estimates", "ashe-table-7-earnings", "cpih01", "mid-year-pop-est"})
+		})
+
+		Convey("they are sorted by release date, most recent first, then by title, with no release date last", func() {
+			sortDatasets(datasets, sortReleaseDate)
+			So(datasetIDs(datasets), ShouldResemble, []string{"cpih01", "ashe-table-7-earnings", "mid-year-pop-est", "ageing-population-estimates"})
+		})
+
+		Convey("they are filtered by every term of the query, in the title or description, ignoring case", func() {
+			So(datasetIDs(filterDatasets(datasets, "POPULATION estimates")), ShouldResemble, []string{"mid-year-pop-est", "ageing-population-estimates"})
+			So(datasetIDs(filterDatasets(datasets, "population age")), ShouldResemble, []string{"ageing-population-estimates"})
+			So(filterDatasets(datasets, "inflation"), ShouldBeEmpty)
+			So(filterDatasets(datasets, "  "), ShouldHaveLength, 4)
+		})
+	})
+
+	Convey("The sort query parameter falls back to title if it is missing or unknown", t, func() {
+		So(getSort(httptest.NewRequest("GET", "/geography/local-authority/E07000223?sort=release_date", nil)), ShouldEqual, sortReleaseDate)
+		So(getSort(httptest.NewRequest("GET", "/geography/local-authority/E07000223?sort=id", nil)), ShouldEqual, sortID)
+		So(getSort(httptest.NewRequest("GET", "/geography/local-authority/E07000223?sort=popularity", nil)), ShouldEqual, sortTitle)
+		So(getSort(httptest.NewRequest("GET", "/geography/local-authority/E07000223", nil)), ShouldEqual, sortTitle)
+	})
+}
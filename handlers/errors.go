@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/renderer"
+
+	"github.com/ONSdigital/dp-frontend-models/model"
+	"github.com/ONSdigital/dp-frontend-models/model/errorPage"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// errorTemplate is the renderer template used for error pages
+const errorTemplate = "error"
+
+// fallbackErrorPage is written when the error page cannot be rendered by the renderer
+const fallbackErrorPage = `<!DOCTYPE html>
+<html lang="%s">
+<head><meta charset="utf-8"><title>%s - Office for National Statistics</title></head>
+<body>
+<h1>%s</h1>
+<p>%s</p>
+<p><a href="/geography">Return to geography</a></p>
+</body>
+</html>
+`
+
+// retryAfter is sent in the Retry-After header when an upstream service is unavailable or limiting our requests
+const retryAfter = 30 * time.Second
+
+// severity is the level an error is logged at
+type severity int
+
+const (
+	severityInfo severity = iota
+	severityWarn
+	severityError
+)
+
+// errorClass describes how an error is reported to the user and in the logs
+type errorClass struct {
+	status     int
+	retryAfter bool
+	severity   severity
+}
+
+// classifyError maps err to the response status and log level it is reported with. Errors from the renderer are
+// always a problem with this service, whereas the status of a code list or dataset API error says who is at fault:
+//   - 400 and 404 are the user asking for something that is malformed or does not exist
+//   - 401 and 403 are a preview user without access to the collection
+//   - 429 and 503 are an upstream service that is temporarily unable to handle our requests, which can be retried
+//
+// Downstream calls that run out of time give a 504, and anything else is an unexpected failure and gives a 500.
+func classifyError(err error) errorClass {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errorClass{status: http.StatusGatewayTimeout, severity: severityWarn}
+	}
+
+	var rendErr renderer.ErrInvalidRendererResponse
+	if errors.As(err, &rendErr) {
+		return errorClass{status: http.StatusInternalServerError, severity: severityError}
+	}
+
+	var cliErr ClientError
+	if !errors.As(err, &cliErr) {
+		return errorClass{status: http.StatusInternalServerError, severity: severityError}
+	}
+
+	switch code := cliErr.Code(); code {
+	case http.StatusBadRequest, http.StatusNotFound:
+		return errorClass{status: code, severity: severityInfo}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errorClass{status: code, severity: severityWarn}
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return errorClass{status: http.StatusServiceUnavailable, retryAfter: true, severity: severityWarn}
+	default:
+		return errorClass{status: http.StatusInternalServerError, severity: severityError}
+	}
+}
+
+// writeErrorPage sets the response status for err and writes an error page. The page model is written as JSON if
+// the request asks for it, otherwise it is rendered with the error template. If the renderer fails, a minimal
+// built-in HTML page is written instead.
+func writeErrorPage(w http.ResponseWriter, req *http.Request, rend RenderClient, lang string, err error, logData log.Data) {
+	ctx := req.Context()
+	status := classifyError(err).status
+	page := mapErrorPage(req, lang, status)
+
+	pageJSON, marshalErr := json.Marshal(page)
+	if marshalErr != nil {
+		log.Error(ctx, "error marshalling error page data to JSON", marshalErr)
+		writeFallbackErrorPage(w, req, page, err, logData)
+		return
+	}
+
+	if negotiateMediaType(req, mediaTypeHTML, mediaTypeJSON) == mediaTypeJSON {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		setStatusCode(req, w, err, logData)
+		w.Write(pageJSON)
+		return
+	}
+
+	pageHTML, renderErr := render(ctx, rend, errorTemplate, pageJSON)
+	if renderErr != nil {
+		log.Error(ctx, "error rendering error page", renderErr, log.Data{"status": status})
+		writeFallbackErrorPage(w, req, page, err, logData)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	setStatusCode(req, w, err, logData)
+	w.Write(pageHTML)
+}
+
+// mapErrorPage maps the response status to the error page model
+func mapErrorPage(req *http.Request, lang string, status int) errorPage.Page {
+	var page errorPage.Page
+
+	mapCookiePreferences(req, &page.CookiesPreferencesSet, &page.CookiesPolicy)
+	page.Type = errorTemplate
+	page.BetaBannerEnabled = true
+	page.Language = lang
+	page.Breadcrumb = []model.TaxonomyNode{
+		{
+			Title: "Home",
+			URI:   "https://www.ons.gov.uk",
+		},
+		{
+			Title: "Geography",
+			URI:   "/geography",
+		},
+	}
+
+	switch status {
+	case http.StatusBadRequest:
+		page.Error.Title = "Sorry, we could not understand the request"
+		page.Error.Description = "Check the address of the page and try again."
+	case http.StatusUnauthorized, http.StatusForbidden:
+		page.Error.Title = "You do not have access to this page"
+		page.Error.Description = "If you are previewing a collection, check that you have access to it."
+	case http.StatusNotFound:
+		page.Error.Title = "Page not found"
+		page.Error.Description = "The page you are looking for may have moved or no longer exists."
+	case http.StatusServiceUnavailable:
+		page.Error.Title = "Sorry, the service is temporarily unavailable"
+		page.Error.Description = "Please try again in a few minutes."
+	case http.StatusGatewayTimeout:
+		page.Error.Title = "Sorry, the page took too long to load"
+		page.Error.Description = "Please try again in a few minutes."
+	default:
+		page.Error.Title = "Sorry, there is a problem with the service"
+		page.Error.Description = "Please try again later."
+	}
+	page.Metadata.Title = page.Error.Title
+
+	return page
+}
+
+func writeFallbackErrorPage(w http.ResponseWriter, req *http.Request, page errorPage.Page, err error, logData log.Data) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	setStatusCode(req, w, err, logData)
+	fmt.Fprintf(w, fallbackErrorPage,
+		html.EscapeString(page.Language),
+		html.EscapeString(page.Error.Title),
+		html.EscapeString(page.Error.Title),
+		html.EscapeString(page.Error.Description),
+	)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-frontend-models/model/errorPage"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteErrorPage(t *testing.T) {
+	Convey("Given a request for a page that does not exist", t, func() {
+		req := httptest.NewRequest("GET", "/geography/local-authority", nil)
+		req.AddCookie(&http.Cookie{Name: "cookies_preferences_set", Value: "true"})
+		w := httptest.NewRecorder()
+		mockRenderClient := &RenderClientMock{
+			DoFunc: func(path string, bytes []byte) ([]byte, error) {
+				return []byte("<html>error page</html>"), nil
+			},
+		}
+
+		Convey("the error template is rendered with the status, language, breadcrumb and cookie preferences", func() {
+			writeErrorPage(w, req, mockRenderClient, "cy", &testCliError{}, nil)
+
+			So(w.Code, ShouldEqual, http.StatusNotFound)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "text/html; charset=utf-8")
+			So(w.Body.String(), ShouldEqual, "<html>error page</html>")
+
+			calls := mockRenderClient.DoCalls()
+			So(calls, ShouldHaveLength, 1)
+			So(calls[0].In1, ShouldEqual, "error")
+
+			var page errorPage.Page
+			So(json.Unmarshal(calls[0].In2, &page), ShouldBeNil)
+			So(page.Type, ShouldEqual, "error")
+			So(page.Language, ShouldEqual, "cy")
+			So(page.CookiesPreferencesSet, ShouldBeTrue)
+			So(page.Error.Title, ShouldEqual, "Page not found")
+			So(page.Breadcrumb, ShouldHaveLength, 2)
+			So(page.Breadcrumb[1].URI, ShouldEqual, "/geography")
+		})
+
+		Convey("a built-in page is written if the renderer fails", func() {
+			mockRenderClient.DoFunc = func(path string, bytes []byte) ([]byte, error) {
+				return nil, errors.New("renderer unavailable")
+			}
+			writeErrorPage(w, req, mockRenderClient, "en", errors.New("code list api unavailable"), nil)
+
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "text/html; charset=utf-8")
+			So(w.Body.String(), ShouldContainSubstring, `<html lang="en">`)
+			So(w.Body.String(), ShouldContainSubstring, "Sorry, there is a problem with the service")
+		})
+
+		Convey("an unavailable upstream service gives a temporarily unavailable page that can be retried", func() {
+			writeErrorPage(w, req, mockRenderClient, "en", testStatusError(http.StatusServiceUnavailable), nil)
+
+			So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+			So(w.Header().Get("Retry-After"), ShouldEqual, "30")
+
+			var page errorPage.Page
+			So(json.Unmarshal(mockRenderClient.DoCalls()[0].In2, &page), ShouldBeNil)
+			So(page.Error.Title, ShouldEqual, "Sorry, the service is temporarily unavailable")
+		})
+
+		Convey("the page model is written as JSON without rendering it if the request accepts JSON", func() {
+			req.Header.Set("Accept", "application/json")
+			writeErrorPage(w, req, mockRenderClient, "en", &testCliError{}, nil)
+
+			So(w.Code, ShouldEqual, http.StatusNotFound)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 0)
+
+			var page errorPage.Page
+			So(json.Unmarshal(w.Body.Bytes(), &page), ShouldBeNil)
+			So(page.Error.Title, ShouldEqual, "Page not found")
+		})
+	})
+}
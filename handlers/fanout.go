@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fanOut calls fn for each index from 0 to n-1, with at most workers calls in flight at once. Each call is given a
+// context that is cancelled after timeout, or when ctx is done, whichever is first; a timeout of zero or less means
+// calls are only bound by ctx. Calls that have not started by the time ctx is done are skipped with ctx's error.
+// The first error to occur is returned once every call has finished or been skipped.
+func fanOut(ctx context.Context, n, workers int, timeout time.Duration, fn func(ctx context.Context, i int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	var firstErr error
+	var mutex sync.Mutex
+	setErr := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					setErr(err)
+					continue
+				}
+				if err := callWithTimeout(ctx, timeout, i, fn); err != nil {
+					setErr(err)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return firstErr
+}
+
+// fanOutObserverKey is the context key of the function that fan-out sizes are reported to
+type fanOutObserverKey struct{}
+
+// WithFanOutObserver returns a copy of ctx, the context of an incoming request, that reports the number of downstream
+// calls in each fan-out made while handling the request to observe, along with the name of the handler making it
+func WithFanOutObserver(ctx context.Context, observe func(handler string, size int)) context.Context {
+	return context.WithValue(ctx, fanOutObserverKey{}, observe)
+}
+
+// observeFanOut reports the size of a fan-out to the observer in ctx, if there is one
+func observeFanOut(ctx context.Context, handler string, size int) {
+	if observe, ok := ctx.Value(fanOutObserverKey{}).(func(handler string, size int)); ok {
+		observe(handler, size)
+	}
+}
+
+func callWithTimeout(ctx context.Context, timeout time.Duration, i int, fn func(ctx context.Context, i int) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return fn(ctx, i)
+}
+
+// withRequestBudget bounds ctx, the context of an incoming request, by the time budget for all of the downstream
+// calls made while handling it. A budget of zero or less leaves ctx unbounded other than by its own deadline.
+func withRequestBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, budget)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFanOut(t *testing.T) {
+	Convey("Given a fan-out of 10 calls over 3 workers", t, func() {
+		var mutex sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		called := make([]bool, 10)
+
+		fn := func(ctx context.Context, i int) error {
+			mutex.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			called[i] = true
+			mutex.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+			return nil
+		}
+
+		Convey("every index is called with no more than 3 calls in flight at once", func() {
+			err := fanOut(context.Background(), 10, 3, 0, fn)
+			So(err, ShouldBeNil)
+			So(maxInFlight, ShouldBeLessThanOrEqualTo, 3)
+			for _, c := range called {
+				So(c, ShouldBeTrue)
+			}
+		})
+	})
+
+	Convey("Given a call that takes longer than the per-call timeout", t, func() {
+		fn := func(ctx context.Context, i int) error {
+			if i == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		}
+
+		Convey("its context is cancelled and its error is returned", func() {
+			err := fanOut(context.Background(), 3, 3, 10*time.Millisecond, fn)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given the first error to occur", t, func() {
+		errFirst := errors.New("first")
+		fn := func(ctx context.Context, i int) error {
+			if i == 0 {
+				return errFirst
+			}
+			return nil
+		}
+
+		Convey("it is returned", func() {
+			So(fanOut(context.Background(), 5, 1, 0, fn), ShouldEqual, errFirst)
+		})
+	})
+
+	Convey("Given a request budget that has run out", t, func() {
+		ctx, cancel := withRequestBudget(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		calls := 0
+
+		Convey("calls that have not started are skipped with the context error", func() {
+			err := fanOut(ctx, 5, 2, 0, func(ctx context.Context, i int) error {
+				calls++
+				return nil
+			})
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			So(calls, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestFanOutObserver(t *testing.T) {
+	Convey("Given a context with a fan-out observer", t, func() {
+		var handlers []string
+		var sizes []int
+		ctx := WithFanOutObserver(context.Background(), func(handler string, size int) {
+			handlers = append(handlers, handler)
+			sizes = append(sizes, size)
+		})
+
+		Convey("fan-out sizes are reported to it, including from derived contexts", func() {
+			observeFanOut(ctx, "homepage", 3)
+			derived, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			observeFanOut(derived, "area_page", 0)
+
+			So(handlers, ShouldResemble, []string{"homepage", "area_page"})
+			So(sizes, ShouldResemble, []int{3, 0})
+		})
+
+		Convey("nothing is reported from a context without one", func() {
+			observeFanOut(context.Background(), "homepage", 3)
+			So(handlers, ShouldBeEmpty)
+		})
+	})
+}
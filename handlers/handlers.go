@@ -7,19 +7,20 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
-	"strings"
-	"sync"
+	"strconv"
 
 	"github.com/ONSdigital/dp-api-clients-go/headers"
 	"github.com/ONSdigital/dp-cookies/cookies"
 	"github.com/ONSdigital/dp-frontend-models/model"
-	"github.com/ONSdigital/dp-frontend-models/model/geography/area"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/models"
 	"github.com/ONSdigital/dp-frontend-models/model/geography/homepage"
 	"github.com/ONSdigital/dp-frontend-models/model/geography/list"
 	dphandlers "github.com/ONSdigital/dp-net/handlers"
 	dprequest "github.com/ONSdigital/dp-net/request"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/ONSdigital/dp-api-clients-go/codelist"
 	"github.com/ONSdigital/dp-api-clients-go/dataset"
@@ -40,6 +41,7 @@ type CodeListClient interface {
 // DatasetClient is an interface with methods required for a dataset client
 type DatasetClient interface {
 	Get(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID string) (m dataset.DatasetDetails, err error)
+	GetVersion(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (m dataset.Version, err error)
 }
 
 // RenderClient is an interface with methods for require for rendering a template
@@ -53,61 +55,119 @@ type ClientError interface {
 	Code() int
 }
 
-func setStatusCode(req *http.Request, w http.ResponseWriter, err error) {
-	status := http.StatusInternalServerError
-	if err, ok := err.(ClientError); ok {
-		if err.Code() == http.StatusNotFound {
-			status = err.Code()
-		}
-		log.Error(req.Context(), "setting response status", err, log.Data{"status": status})
+// setStatusCode writes the response status for err, with a Retry-After header if the request can be retried later,
+// and logs err with logData at the level of its class. This is the only place that the errors of a request are
+// logged, so that expected errors such as 404s are not logged as errors.
+func setStatusCode(req *http.Request, w http.ResponseWriter, err error, logData log.Data) {
+	class := classifyError(err)
+	data := log.Data{"status": class.status}
+	for k, v := range logData {
+		data[k] = v
+	}
+	switch class.severity {
+	case severityInfo:
+		log.Info(req.Context(), "setting response status", data, log.FormatErrors([]error{err}))
+	case severityWarn:
+		log.Warn(req.Context(), "setting response status", data, log.FormatErrors([]error{err}))
+	default:
+		log.Error(req.Context(), "setting response status", err, data)
+	}
+	if class.retryAfter {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	}
+	w.WriteHeader(class.status)
+}
+
+// writePage writes the page model as JSON if the request asks for it, otherwise it renders the page model with the
+// template and writes the resulting HTML. The response has an ETag computed from the page model. If the request's
+// If-None-Match shows the client already has the page, 304 Not Modified is written without rendering the page.
+func writePage(w http.ResponseWriter, req *http.Request, rend RenderClient, lang, templateName string, page interface{}, logData log.Data) {
+	ctx := req.Context()
+	if logData == nil {
+		logData = log.Data{}
+	}
+	logData["template"] = templateName
+
+	pageJSON, err := json.Marshal(page)
+	if err != nil {
+		writeErrorPage(w, req, rend, lang, err, logData)
+		return
+	}
+
+	mediaType := negotiateMediaType(req, mediaTypeHTML, mediaTypeJSON)
+	etag := pageETag(templateName, mediaType, pageJSON)
+	if notModified(req, etag) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if mediaType == mediaTypeJSON {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(pageJSON)
+		return
 	}
-	w.WriteHeader(status)
+
+	pageHTML, err := render(ctx, rend, templateName, pageJSON)
+	if err != nil {
+		writeErrorPage(w, req, rend, lang, err, logData)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(pageHTML)
 }
 
 //HomepageRender gets geography data from the code-list-api and formats for rendering
-func HomepageRender(rend RenderClient, cli CodeListClient) http.HandlerFunc {
+func HomepageRender(cfg config.Config, rend RenderClient, cli CodeListClient) http.HandlerFunc {
 	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, collectionID, userAuthToken string) {
-		ctx := req.Context()
+		ctx, cancel := withRequestBudget(withCollectionID(req.Context(), collectionID), cfg.RequestBudget)
+		defer cancel()
 		var page homepage.Page
 
 		serviceAuthToken := getServiceAuthToken(req)
 
 		codeListResults, err := cli.GetGeographyCodeLists(ctx, userAuthToken, serviceAuthToken)
 		if err != nil {
-			log.Error(ctx, "error getting geography code-lists", err)
-			setStatusCode(req, w, err)
+			writeErrorPage(w, req, rend, lang, err, nil)
 			return
 		}
 
-		var types []homepage.Item
-		var wg sync.WaitGroup
-		var mutex = &sync.Mutex{}
-		for _, v := range codeListResults.Items {
-			wg.Add(1)
-			go func(codeListResults codelist.CodeListResults, cli CodeListClient, v codelist.CodeList) {
-				defer wg.Done()
-				typesID := v.Links.Self.ID
-				editionsListResults, err := cli.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, typesID)
-				if err != nil {
-					log.Error(ctx, "Error doing GET editions for code-list", err, log.Data{
-						"codeListID": typesID,
-					})
-					return
-				}
+		results := make([]*homepage.Item, len(codeListResults.Items))
+		fanOutCtx, span := startFanOut(ctx, "homepage", len(codeListResults.Items))
+		err = fanOut(fanOutCtx, len(codeListResults.Items), cfg.HomepageWorkers, cfg.HomepageCallTimeout, func(ctx context.Context, i int) error {
+			typesID := codeListResults.Items[i].Links.Self.ID
+			editionsListResults, err := cli.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, typesID)
+			if err != nil {
+				log.Error(ctx, "Error doing GET editions for code-list", err, log.Data{
+					"codeListID": typesID,
+				})
+				return err
+			}
 
-				if len(editionsListResults.Items) > 0 && editionsListResults.Items[0].Label != "" {
-					mutex.Lock()
-					defer mutex.Unlock()
-					types = append(types, homepage.Item{
-						Label: editionsListResults.Items[0].Label,
-						ID:    typesID,
-						URI:   fmt.Sprintf("/geography/%s", typesID),
-					})
+			if len(editionsListResults.Items) > 0 && editionsListResults.Items[0].Label != "" {
+				results[i] = &homepage.Item{
+					Label: editionsListResults.Items[0].Label,
+					ID:    typesID,
+					URI:   fmt.Sprintf("/geography/%s", typesID),
 				}
-				return
-			}(codeListResults, cli, v)
+			}
+			return nil
+		})
+		EndSpan(span, err)
+		if err != nil {
+			log.Warn(ctx, "error getting code list editions, rendering the geography types that are available", log.FormatErrors([]error{err}))
+			preventCaching(w)
+		}
+
+		var types []homepage.Item
+		for _, item := range results {
+			if item != nil {
+				types = append(types, *item)
+			}
 		}
-		wg.Wait()
 
 		sort.Slice(types, func(i, j int) bool {
 			return types[i].Label < types[j].Label
@@ -129,71 +189,94 @@ func HomepageRender(rend RenderClient, cli CodeListClient) http.HandlerFunc {
 			},
 		}
 
-		templateJSON, err := json.Marshal(page)
-		if err != nil {
-			log.Error(ctx, "error marshaling geography code-lists page data", err)
-			setStatusCode(req, w, err)
-			return
-		}
-		templateHTML, err := rend.Do("geography-homepage", templateJSON)
-		if err != nil {
-			log.Error(ctx, "error rendering homepage", err)
-			setStatusCode(req, w, err)
-			return
-		}
-
-		w.Write(templateHTML)
-		return
+		writePage(w, req, rend, lang, "geography-homepage", page, nil)
 	})
 }
 
-//ListPageRender renders a list of codes associated to the first edition of a code-list
-func ListPageRender(rend RenderClient, cli CodeListClient) http.HandlerFunc {
+//ListPageRender renders a page of the codes associated to the requested edition of a code-list, or the first edition if none was requested
+func ListPageRender(cfg config.Config, rend RenderClient, cli CodeListClient) http.HandlerFunc {
 	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, collectionID, userAuthToken string) {
 
-		ctx := req.Context()
+		ctx := withCollectionID(req.Context(), collectionID)
 		vars := mux.Vars(req)
 		codeListID := vars["codeListID"]
 		logData := log.Data{
 			codeListID: codeListID,
 		}
-		var page list.Page
+		setSpanAttributes(ctx, attribute.String("code_list_id", codeListID))
+		var page models.ListPage
 		serviceAuthToken := getServiceAuthToken(req)
+		requestedEdition := getRequestedEdition(req)
+		currentPage, limit := getPaginationParams(req, cfg)
+		mediaType := negotiateMediaType(req, mediaTypeHTML, mediaTypeJSON, mediaTypeCSV)
 
 		codeListEditions, err := cli.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
 		if err != nil {
-			log.Error(ctx, "error getting editions for a code-list", err, logData)
-			setStatusCode(req, w, err)
+			writeErrorPage(w, req, rend, lang, err, logData)
+			return
+		}
+
+		edition, err := selectEdition(codeListEditions.Items, requestedEdition)
+		if err != nil {
+			logData["edition"] = requestedEdition
+			writeErrorPage(w, req, rend, lang, err, logData)
 			return
 		}
 
-		if codeListEditions.Count > 0 {
-			edition := codeListEditions.Items[0]
+		if edition == nil && mediaType == mediaTypeCSV {
+			err = notFoundError{message: fmt.Sprintf("code-list %s has no editions", codeListID)}
+			writeErrorPage(w, req, rend, lang, err, logData)
+			return
+		}
+
+		if edition != nil {
+			setSpanAttributes(ctx, attribute.String("edition", edition.Edition))
 			page.Metadata.Title = edition.Label
+			page.Data.Edition = edition.Edition
+			page.Data.Editions = mapEditions(codeListEditions.Items, edition.Edition, func(e string) string {
+				return fmt.Sprintf("/geography/%s/editions/%s", codeListID, e)
+			})
 
 			log.Info(ctx, "getting codes for edition of a code list", log.Data{"edition": edition})
 			codes, err := cli.GetCodes(ctx, userAuthToken, serviceAuthToken, codeListID, edition.Edition)
 			if err != nil {
 				logData["edition"] = edition.Edition
-				log.Error(ctx, "error getting codes for an edition of a code-list", err, logData)
-				setStatusCode(req, w, err)
+				writeErrorPage(w, req, rend, lang, err, logData)
 				return
 			}
 
-			if codes.Count > 0 {
-				var pageCodes []list.Item
-				for _, item := range codes.Items {
-					pageCodes = append(pageCodes, list.Item{
-						ID:    item.Code,
-						Label: item.Label,
-						URI:   fmt.Sprintf("/geography/%s/%s", codeListID, item.Code),
-					})
-				}
-				sort.Slice(pageCodes[:], func(i, j int) bool {
-					return pageCodes[i].Label < pageCodes[j].Label
+			if mediaType == mediaTypeCSV {
+				writeCodesCSV(ctx, w, codeListID, edition.Edition, codes.Items)
+				return
+			}
+
+			var pageCodes []list.Item
+			for _, item := range codes.Items {
+				pageCodes = append(pageCodes, list.Item{
+					ID:    item.Code,
+					Label: item.Label,
+					URI:   withEditionQuery(fmt.Sprintf("/geography/%s/%s", codeListID, item.Code), requestedEdition),
 				})
+			}
+			sort.Slice(pageCodes[:], func(i, j int) bool {
+				return pageCodes[i].Label < pageCodes[j].Label
+			})
 
-				page.Data.Items = pageCodes
+			pagination, err := paginate(req, currentPage, limit, len(pageCodes))
+			if err != nil {
+				logData["page"] = currentPage
+				writeErrorPage(w, req, rend, lang, err, logData)
+				return
+			}
+			page.Data.Pagination = pagination
+
+			offset := (currentPage - 1) * limit
+			if offset < len(pageCodes) {
+				end := offset + limit
+				if end > len(pageCodes) {
+					end = len(pageCodes)
+				}
+				page.Data.Items = pageCodes[offset:end]
 			}
 		}
 		mapCookiePreferences(req, &page.CookiesPreferencesSet, &page.CookiesPolicy)
@@ -214,29 +297,17 @@ func ListPageRender(rend RenderClient, cli CodeListClient) http.HandlerFunc {
 			},
 		}
 
-		templateJSON, err := json.Marshal(page)
-		if err != nil {
-			log.Error(ctx, "error marshalling geography list page data to JSON", err, logData)
-			setStatusCode(req, w, err)
-			return
-		}
-		templateHTML, err := rend.Do("geography-list", templateJSON)
-		if err != nil {
-			log.Error(ctx, "error getting HTML of list of geographic areas", err, logData)
-			setStatusCode(req, w, err)
-			return
-		}
-
-		w.Write(templateHTML)
-		return
+		// the code list API has no dates for code lists or their editions, so the last modified time is unknown
+		writePage(w, req, rend, lang, "geography-list", page, logData)
 	})
 }
 
 //AreaPageRender gets data about a specific code, get what datasets are associated with the code and get information
 // about those datasets, maps it and passes it to the renderer
-func AreaPageRender(rend RenderClient, cli CodeListClient, dcli DatasetClient, apiRouterVersion string) http.HandlerFunc {
+func AreaPageRender(cfg config.Config, rend RenderClient, cli CodeListClient, dcli DatasetClient, apiRouterVersion string) http.HandlerFunc {
 	return dphandlers.ControllerHandler(func(w http.ResponseWriter, req *http.Request, lang, collectionID, userAuthToken string) {
-		ctx := req.Context()
+		ctx, cancel := withRequestBudget(withCollectionID(req.Context(), collectionID), cfg.RequestBudget)
+		defer cancel()
 		vars := mux.Vars(req)
 		codeListID := vars["codeListID"]
 		codeID := vars["codeID"]
@@ -245,78 +316,67 @@ func AreaPageRender(rend RenderClient, cli CodeListClient, dcli DatasetClient, a
 			codeListID: codeListID,
 			codeID:     codeID,
 		}
+		setSpanAttributes(ctx, attribute.String("code_list_id", codeListID), attribute.String("code_id", codeID))
 
-		var page area.Page
+		var page models.AreaPage
 		serviceAuthToken := getServiceAuthToken(req)
+		requestedEdition := getRequestedEdition(req)
+		page.Data.Sort = getSort(req)
+		page.Data.Query = req.URL.Query().Get("q")
 
 		codeListEditions, err := cli.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
 		if err != nil {
-			log.Error(ctx, "error getting editions for a code-list", err, logData)
-			setStatusCode(req, w, err)
+			writeErrorPage(w, req, rend, lang, err, logData)
+			return
+		}
+
+		edition, err := selectEdition(codeListEditions.Items, requestedEdition)
+		if err != nil {
+			logData["edition"] = requestedEdition
+			writeErrorPage(w, req, rend, lang, err, logData)
 			return
 		}
 
 		var parentName string
 
-		if codeListEditions.Count > 0 {
-			edition := codeListEditions.Items[0]
+		if edition != nil {
+			setSpanAttributes(ctx, attribute.String("edition", edition.Edition))
 			parentName = edition.Label
+			page.Data.Edition = edition.Edition
+			page.Data.Editions = mapEditions(codeListEditions.Items, edition.Edition, func(e string) string {
+				return withEditionQuery(fmt.Sprintf("/geography/%s/%s", codeListID, codeID), e)
+			})
 
 			log.Info(ctx, "getting data about code", log.Data{"edition": edition})
 			codeData, err := cli.GetCodeByID(ctx, userAuthToken, serviceAuthToken, codeListID, edition.Edition, codeID)
 			if err != nil {
-				log.Error(ctx, "error getting code data", err, logData)
-				setStatusCode(req, w, err)
+				writeErrorPage(w, req, rend, lang, err, logData)
 				return
 			}
 			page.Metadata.Title = codeData.Label
 
 			datasetsResp, err := cli.GetDatasetsByCode(ctx, userAuthToken, serviceAuthToken, codeListID, edition.Edition, codeID)
 			if err != nil {
-				log.Error(ctx, "error getting datasets related to code", err, logData)
-				setStatusCode(req, w, err)
+				writeErrorPage(w, req, rend, lang, err, logData)
 				return
 			}
 
-			if datasetsResp.Count > 0 {
-				var datasets []area.Dataset
-				var wg sync.WaitGroup
-				var mutex = &sync.Mutex{}
-				var gotErr bool
-				for _, datasetResp := range datasetsResp.Datasets {
-					wg.Add(1)
-					go func(ctx context.Context, dcli DatasetClient, datasetResp codelist.Dataset) {
-						defer wg.Done()
-						datasetDetails, err := dcli.Get(ctx, userAuthToken, serviceAuthToken, collectionID, datasetResp.Links.Self.ID)
-						if err != nil {
-							gotErr = true
-							log.Error(ctx, "error getting dataset", err, logData)
-							return
-						}
-						datasetWebsiteURL, err := url.Parse(datasetResp.Editions[0].Links.LatestVersion.Href)
-						if err != nil {
-							gotErr = true
-							log.Error(ctx, "error parsing dataset href", err, logData)
-							return
-						}
-						mutex.Lock()
-						defer mutex.Unlock()
-						datasetWebsitePath := strings.TrimPrefix(datasetWebsiteURL.Path, apiRouterVersion)
-						datasets = append(datasets, area.Dataset{
-							ID:          datasetResp.Editions[0].Links.Self.ID,
-							Label:       datasetDetails.Title,
-							Description: datasetDetails.Description,
-							URI:         datasetWebsitePath,
-						})
+			entries, skipped := validateDatasetEntries(ctx, datasetsResp.Datasets, apiRouterVersion, logData)
+			page.Data.SkippedDatasets = skipped
 
+			if len(entries) > 0 {
+				datasets, err := getDatasets(ctx, cfg, dcli, userAuthToken, serviceAuthToken, collectionID, entries, page.Data.Sort == sortReleaseDate)
+				if err != nil {
+					if cfg.AreaPageDatasetFailurePolicy != config.DatasetFailurePolicyPartial {
+						writeErrorPage(w, req, rend, lang, err, logData)
 						return
-					}(ctx, dcli, datasetResp)
-				}
-				wg.Wait()
-				if gotErr {
-					setStatusCode(req, w, err)
-					return
+					}
+					log.Warn(ctx, "error getting datasets, rendering the datasets that are available", logData, log.FormatErrors([]error{err}))
+					page.Data.DatasetsUnavailable = true
+					preventCaching(w)
 				}
+				datasets = filterDatasets(datasets, page.Data.Query)
+				sortDatasets(datasets, page.Data.Sort)
 				page.Data.Datasets = datasets
 			}
 		}
@@ -327,22 +387,122 @@ func AreaPageRender(rend RenderClient, cli CodeListClient, dcli DatasetClient, a
 		page.Language = lang
 		page.Breadcrumb = getAreaPageRenderBreadcrumb(parentName, page.Metadata.Title, codeListID, codeID)
 
-		templateJSON, err := json.Marshal(page)
-		if err != nil {
-			log.Error(ctx, "error marshalling geography area page data to JSON", err, logData)
-			setStatusCode(req, w, err)
-			return
+		writePage(w, req, rend, lang, "geography-area", page, logData)
+	})
+}
+
+// notFoundError is returned when a request refers to something that does not exist, such as an unknown edition
+type notFoundError struct {
+	message string
+}
+
+func (e notFoundError) Error() string {
+	return e.message
+}
+
+// Code returns the status code used when responding to a request for something that does not exist
+func (e notFoundError) Code() int {
+	return http.StatusNotFound
+}
+
+// getRequestedEdition returns the edition requested in either the route or the edition query parameter
+func getRequestedEdition(req *http.Request) string {
+	if edition, ok := mux.Vars(req)["edition"]; ok {
+		return edition
+	}
+	return req.URL.Query().Get("edition")
+}
+
+// selectEdition returns the requested edition, or the first edition if none was requested. A nil edition is
+// returned when the code-list has no editions and none was requested.
+func selectEdition(editions []codelist.EditionsList, requested string) (*codelist.EditionsList, error) {
+	if requested == "" {
+		if len(editions) == 0 {
+			return nil, nil
 		}
-		templateHTML, err := rend.Do("geography-area", templateJSON)
-		if err != nil {
-			log.Error(ctx, "error getting HTML of geographic area page", err, logData)
-			setStatusCode(req, w, err)
-			return
+		return &editions[0], nil
+	}
+
+	for i := range editions {
+		if editions[i].Edition == requested {
+			return &editions[i], nil
 		}
+	}
+	return nil, notFoundError{message: fmt.Sprintf("edition %s not found", requested)}
+}
 
-		w.Write(templateHTML)
-		return
-	})
+// mapEditions maps the editions of a code-list to the page model, using uri to build the link to each edition
+func mapEditions(editions []codelist.EditionsList, selected string, uri func(edition string) string) []models.Edition {
+	var mapped []models.Edition
+	for _, e := range editions {
+		mapped = append(mapped, models.Edition{
+			Edition:  e.Edition,
+			Label:    e.Label,
+			URI:      uri(e.Edition),
+			Selected: e.Edition == selected,
+		})
+	}
+	return mapped
+}
+
+// withEditionQuery adds the edition query parameter to uri, if an edition has been provided
+func withEditionQuery(uri, edition string) string {
+	if edition == "" {
+		return uri
+	}
+	return uri + "?edition=" + url.QueryEscape(edition)
+}
+
+// getPaginationParams returns the page and limit requested in the query parameters. Missing or invalid values fall
+// back to the first page and the configured default limit, and the limit is capped at the configured maximum.
+func getPaginationParams(req *http.Request, cfg config.Config) (page int, limit int) {
+	query := req.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err = strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 1 {
+		limit = cfg.ListPageDefaultLimit
+	}
+	if limit > cfg.ListPageMaxLimit {
+		limit = cfg.ListPageMaxLimit
+	}
+	return page, limit
+}
+
+// paginate returns the pagination metadata for the requested page of a list of totalCount items. The previous and
+// next links keep any other query parameters of the request.
+func paginate(req *http.Request, page, limit, totalCount int) (models.Pagination, error) {
+	totalPages := (totalCount + limit - 1) / limit
+	if totalPages < 1 {
+		totalPages = 1
+	}
+	if page > totalPages {
+		return models.Pagination{}, notFoundError{message: fmt.Sprintf("page %d not found", page)}
+	}
+
+	pageURI := func(p int) string {
+		query := req.URL.Query()
+		query.Set("page", strconv.Itoa(p))
+		return req.URL.Path + "?" + query.Encode()
+	}
+
+	pagination := models.Pagination{
+		CurrentPage: page,
+		TotalPages:  totalPages,
+		Limit:       limit,
+		TotalCount:  totalCount,
+	}
+	if page > 1 {
+		pagination.PreviousURI = pageURI(page - 1)
+	}
+	if page < totalPages {
+		pagination.NextURI = pageURI(page + 1)
+	}
+	return pagination, nil
 }
 
 func getAreaPageRenderBreadcrumb(parentName string, pageTitle string, codeListID string, codeID string) []model.TaxonomyNode {
@@ -382,6 +542,14 @@ func getUserAuthToken(ctx context.Context, req *http.Request) string {
 	return cookie.Value
 }
 
+// withCollectionID stores the collection ID in the context, so that client decorators can tell preview requests apart
+func withCollectionID(ctx context.Context, collectionID string) context.Context {
+	if collectionID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, dprequest.CollectionIDContextKey, collectionID)
+}
+
 func getServiceAuthToken(req *http.Request) string {
 	token, _ := headers.GetServiceAuthToken(req)
 	return token
@@ -1,20 +1,30 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ONSdigital/dp-api-clients-go/codelist"
 	"github.com/ONSdigital/dp-api-clients-go/dataset"
 	"github.com/ONSdigital/dp-api-clients-go/headers"
+	"github.com/ONSdigital/dp-api-clients-go/renderer"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/models"
 	"github.com/ONSdigital/dp-frontend-models/model"
 	"github.com/ONSdigital/dp-frontend-models/model/geography/area"
+	"github.com/ONSdigital/dp-frontend-models/model/geography/homepage"
 	"github.com/ONSdigital/dp-frontend-models/model/geography/list"
 	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -24,11 +34,60 @@ var (
 	serviceAccessToken = "Death of mother earth Never a rebirth Evolution's end Never will it mend never"
 )
 
+var testEditions = codelist.EditionsListResults{
+	Items: []codelist.EditionsList{
+		{
+			Edition: "2018",
+			Label:   "Local authority districts",
+		},
+		{
+			Edition: "2017",
+			Label:   "Local authority districts 2017",
+		},
+	},
+	Count:      2,
+	TotalCount: 2,
+}
+
+func testConfig() config.Config {
+	cfg, err := config.Get()
+	So(err, ShouldBeNil)
+	return *cfg
+}
+
 type testCliError struct{}
 
 func (e *testCliError) Error() string { return "client error" }
 func (e *testCliError) Code() int     { return http.StatusNotFound }
 
+type testStatusError int
+
+func (e testStatusError) Error() string { return http.StatusText(int(e)) }
+func (e testStatusError) Code() int     { return int(e) }
+
+func testDatasetByCode(datasetID, edition string) codelist.Dataset {
+	return codelist.Dataset{
+		Links: codelist.DatasetLinks{
+			Self: codelist.Link{ID: datasetID},
+		},
+		Editions: []codelist.DatasetEdition{
+			testDatasetEdition(datasetID, edition, "1", "http://localhost:22000"),
+		},
+	}
+}
+
+func testDatasetEdition(datasetID, edition, latestVersion, apiURL string) codelist.DatasetEdition {
+	return codelist.DatasetEdition{
+		Links: codelist.DatasetEditionLink{
+			Self: codelist.Link{ID: edition},
+			LatestVersion: codelist.Link{
+				ID:   latestVersion,
+				Href: fmt.Sprintf("%s/datasets/%s/editions/%s/versions/%s", apiURL, datasetID, edition, latestVersion),
+			},
+		},
+	}
+}
+
 func assertAuthTokens(actualUserToken, actualServiceToken string) {
 	So(actualUserToken, ShouldEqual, userAccessToken)
 	So(actualServiceToken, ShouldEqual, serviceAccessToken)
@@ -43,17 +102,86 @@ func TestHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			err := &testCliError{}
 
-			setStatusCode(req, w, err)
+			setStatusCode(req, w, err, nil)
 
 			So(w.Code, ShouldEqual, http.StatusNotFound)
 		})
 
+		Convey("test status code logs a 404 once, at info level, with the request's log data", func() {
+			var buf bytes.Buffer
+			log.SetDestination(&buf, nil)
+			defer log.SetDestination(os.Stdout, nil)
+
+			req := httptest.NewRequest("GET", "/foobar", nil)
+			w := httptest.NewRecorder()
+
+			setStatusCode(req, w, &testCliError{}, log.Data{"code_list_id": "local-authority"})
+
+			events := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			So(events, ShouldHaveLength, 1)
+			var event struct {
+				Severity int      `json:"severity"`
+				Data     log.Data `json:"data"`
+			}
+			So(json.Unmarshal([]byte(events[0]), &event), ShouldBeNil)
+			So(event.Severity, ShouldEqual, int(log.INFO))
+			So(event.Data["status"], ShouldEqual, float64(http.StatusNotFound))
+			So(event.Data["code_list_id"], ShouldEqual, "local-authority")
+		})
+
 		Convey("test status code handles internal server error", func() {
 			req := httptest.NewRequest("GET", "/foobar", nil)
 			w := httptest.NewRecorder()
 			err := errors.New("internal server error")
 
-			setStatusCode(req, w, err)
+			setStatusCode(req, w, err, nil)
+
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Convey("test status code passes through client errors caused by the request", func() {
+			for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden} {
+				req := httptest.NewRequest("GET", "/foobar", nil)
+				w := httptest.NewRecorder()
+
+				setStatusCode(req, w, fmt.Errorf("wrapped: %w", testStatusError(status)), nil)
+
+				So(w.Code, ShouldEqual, status)
+				So(w.Header().Get("Retry-After"), ShouldBeEmpty)
+			}
+		})
+
+		Convey("test status code handles an unavailable or rate limiting upstream service", func() {
+			for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+				req := httptest.NewRequest("GET", "/foobar", nil)
+				w := httptest.NewRecorder()
+
+				setStatusCode(req, w, testStatusError(status), nil)
+
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+				So(w.Header().Get("Retry-After"), ShouldEqual, "30")
+			}
+		})
+
+		Convey("test status code handles other upstream errors as internal server errors", func() {
+			req := httptest.NewRequest("GET", "/foobar", nil)
+			w := httptest.NewRecorder()
+
+			setStatusCode(req, w, testStatusError(http.StatusBadGateway), nil)
+
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Convey("test status code handles renderer errors as internal server errors", func() {
+			srv := httptest.NewServer(http.NotFoundHandler())
+			defer srv.Close()
+			_, err := renderer.New(srv.URL).Do("list", nil)
+			So(err, ShouldHaveSameTypeAs, renderer.ErrInvalidRendererResponse{})
+
+			req := httptest.NewRequest("GET", "/foobar", nil)
+			w := httptest.NewRecorder()
+
+			setStatusCode(req, w, err, nil)
 
 			So(w.Code, ShouldEqual, http.StatusInternalServerError)
 		})
@@ -79,7 +207,7 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography").HandlerFunc(HomepageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography").HandlerFunc(HomepageRender(testConfig(), mockRenderClient, mockCodeListClient))
 
 			router.ServeHTTP(w, req)
 
@@ -91,6 +219,118 @@ func TestHandler(t *testing.T) {
 			assertAuthTokens(calls[0].UserAuthToken, calls[0].ServiceAuthToken)
 		})
 
+		Convey("writes the page model as JSON without rendering it if the request accepts JSON", func() {
+			req.Header.Set("Accept", "application/json")
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+					return codelist.CodeListResults{}, nil
+				},
+			}
+
+			router.Path("/geography").HandlerFunc(HomepageRender(testConfig(), mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 0)
+
+			var payload homepage.Page
+			So(json.Unmarshal(w.Body.Bytes(), &payload), ShouldBeNil)
+			So(payload.Metadata.Title, ShouldEqual, "Geography")
+		})
+
+		Convey("responds 304 without rendering if the request already has the page", func() {
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return []byte("<html>geography</html>"), nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+					return codelist.CodeListResults{}, nil
+				},
+			}
+			router.Path("/geography").HandlerFunc(HomepageRender(testConfig(), mockRenderClient, mockCodeListClient))
+
+			router.ServeHTTP(w, req)
+			So(w.Code, ShouldEqual, http.StatusOK)
+			etag := w.Header().Get("ETag")
+			So(etag, ShouldStartWith, `"`)
+			So(w.Header().Get("Last-Modified"), ShouldBeEmpty)
+
+			req.Header.Set("If-None-Match", etag)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, http.StatusNotModified)
+			So(w.Header().Get("ETag"), ShouldEqual, etag)
+			So(w.Body.Len(), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+		})
+
+		Convey("when the editions of one of several code lists cannot be retrieved", func() {
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+					return codelist.CodeListResults{
+						Items: []codelist.CodeList{
+							{Links: codelist.CodeListLinks{Self: &codelist.Link{ID: "local-authority"}}},
+							{Links: codelist.CodeListLinks{Self: &codelist.Link{ID: "country"}}},
+						},
+					}, nil
+				},
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					if codeListID == "country" {
+						return codelist.EditionsListResults{}, testStatusError(http.StatusServiceUnavailable)
+					}
+					return codelist.EditionsListResults{Items: []codelist.EditionsList{{Label: "Local authority"}}}, nil
+				},
+			}
+			cfg := testConfig()
+			req, _ = http.NewRequest("GET", "/geography", nil)
+			headers.SetServiceAuthToken(req, serviceAccessToken)
+			assertPartialPage := func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Cache-Control"), ShouldEqual, "no-store")
+				So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+				So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "geography-homepage")
+
+				var payload homepage.Page
+				So(json.Unmarshal(mockRenderClient.DoCalls()[0].In2, &payload), ShouldBeNil)
+				So(payload.Data.Items, ShouldHaveLength, 1)
+				So(payload.Data.Items[0].ID, ShouldEqual, "local-authority")
+			}
+
+			Convey("the geography types that were retrieved are rendered on a page that must not be stored", func() {
+				router.Path("/geography").HandlerFunc(CacheControl(cfg.HomepageCacheControl, HomepageRender(cfg, mockRenderClient, mockCodeListClient)))
+				router.ServeHTTP(w, req)
+				assertPartialPage()
+			})
+
+			Convey("a code list that takes longer than the per-call timeout is left off a page that must not be stored", func() {
+				mockCodeListClient.GetCodeListEditionsFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					if codeListID == "country" {
+						<-ctx.Done()
+						return codelist.EditionsListResults{}, ctx.Err()
+					}
+					return codelist.EditionsListResults{Items: []codelist.EditionsList{{Label: "Local authority"}}}, nil
+				}
+				cfg.HomepageCallTimeout = 10 * time.Millisecond
+				router.Path("/geography").HandlerFunc(CacheControl(cfg.HomepageCacheControl, HomepageRender(cfg, mockRenderClient, mockCodeListClient)))
+				router.ServeHTTP(w, req)
+				assertPartialPage()
+			})
+		})
+
 		Convey("return a 404 status if request to GET code-list return's a 404", func() {
 			mockRenderClient := &RenderClientMock{
 				DoFunc: func(path string, bytes []byte) ([]byte, error) {
@@ -103,12 +343,13 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography").HandlerFunc(HomepageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography").HandlerFunc(HomepageRender(testConfig(), mockRenderClient, mockCodeListClient))
 
 			router.ServeHTTP(w, req)
 
 			So(w.Code, ShouldEqual, 404)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
 
 			calls := mockCodeListClient.GetGeographyCodeListsCalls()
 			So(calls, ShouldHaveLength, 1)
@@ -127,11 +368,12 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography").HandlerFunc(HomepageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography").HandlerFunc(HomepageRender(testConfig(), mockRenderClient, mockCodeListClient))
 			router.ServeHTTP(w, req)
 
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
 
 			calls := mockCodeListClient.GetGeographyCodeListsCalls()
 			So(calls, ShouldHaveLength, 1)
@@ -150,10 +392,11 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography").HandlerFunc(HomepageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography").HandlerFunc(HomepageRender(testConfig(), mockRenderClient, mockCodeListClient))
 			router.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 1)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 2)
+			So(w.Body.String(), ShouldContainSubstring, "Sorry, there is a problem with the service")
 
 			calls := mockCodeListClient.GetGeographyCodeListsCalls()
 			So(calls, ShouldHaveLength, 1)
@@ -183,7 +426,7 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
 
 			router.ServeHTTP(w, req)
 
@@ -247,7 +490,7 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
 
 			router.ServeHTTP(w, req)
 
@@ -317,11 +560,12 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
 			router.ServeHTTP(w, req)
 
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
 
 			calls := mockCodeListClient.GetCodeListEditionsCalls()
 			So(calls, ShouldHaveLength, 1)
@@ -358,11 +602,12 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
 			router.ServeHTTP(w, req)
 
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
 
 			calls := mockCodeListClient.GetCodeListEditionsCalls()
 			So(calls, ShouldHaveLength, 1)
@@ -388,11 +633,12 @@ func TestHandler(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(mockRenderClient, mockCodeListClient))
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
 			router.ServeHTTP(w, req)
 
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 1)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 2)
+			So(w.Body.String(), ShouldContainSubstring, "Sorry, there is a problem with the service")
 
 			calls := mockCodeListClient.GetCodeListEditionsCalls()
 			So(calls, ShouldHaveLength, 1)
@@ -401,6 +647,203 @@ func TestHandler(t *testing.T) {
 			getCodesCalls := mockCodeListClient.GetCodesCalls()
 			So(getCodesCalls, ShouldBeNil)
 		})
+
+		Convey("renders the edition requested in the edition query parameter", func() {
+			req, _ := http.NewRequest("GET", "/geography/local-authority?edition=2017", nil)
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+					return codelist.CodesResults{
+						Items: []codelist.Item{{Code: "E06000028", Label: "Bournemouth"}},
+						Count: 1,
+					}, nil
+				},
+			}
+
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			getCodesCalls := mockCodeListClient.GetCodesCalls()
+			So(getCodesCalls, ShouldHaveLength, 1)
+			So(getCodesCalls[0].Edition, ShouldEqual, "2017")
+
+			var payload models.ListPage
+			So(json.Unmarshal(mockRenderClient.DoCalls()[0].In2, &payload), ShouldBeNil)
+			So(payload.Metadata.Title, ShouldEqual, "Local authority districts 2017")
+			So(payload.Data.Edition, ShouldEqual, "2017")
+			So(payload.Data.Editions, ShouldResemble, []models.Edition{
+				{
+					Edition: "2018",
+					Label:   "Local authority districts",
+					URI:     "/geography/local-authority/editions/2018",
+				},
+				{
+					Edition:  "2017",
+					Label:    "Local authority districts 2017",
+					URI:      "/geography/local-authority/editions/2017",
+					Selected: true,
+				},
+			})
+			So(payload.Data.Items[0].URI, ShouldEqual, "/geography/local-authority/E06000028?edition=2017")
+		})
+
+		Convey("renders the edition requested in the route", func() {
+			req, _ := http.NewRequest("GET", "/geography/local-authority/editions/2017", nil)
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+					return codelist.CodesResults{}, nil
+				},
+			}
+
+			router.Path("/geography/{codeListID}/editions/{edition}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, 200)
+			getCodesCalls := mockCodeListClient.GetCodesCalls()
+			So(getCodesCalls, ShouldHaveLength, 1)
+			So(getCodesCalls[0].Edition, ShouldEqual, "2017")
+		})
+
+		Convey("return a 404 status if the requested edition does not exist", func() {
+			req, _ := http.NewRequest("GET", "/geography/local-authority?edition=1999", nil)
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+			}
+
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, 404)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
+			So(mockCodeListClient.GetCodesCalls(), ShouldBeNil)
+		})
+
+		Convey("renders the requested page of codes with links to the previous and next pages", func() {
+			req, _ := http.NewRequest("GET", "/geography/local-authority?edition=2018&limit=2&page=2", nil)
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+					return codelist.CodesResults{
+						Items: []codelist.Item{
+							{Code: "E1", Label: "A"},
+							{Code: "E2", Label: "B"},
+							{Code: "E3", Label: "C"},
+							{Code: "E4", Label: "D"},
+							{Code: "E5", Label: "E"},
+						},
+						Count:      5,
+						TotalCount: 5,
+					}, nil
+				},
+			}
+
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			var payload models.ListPage
+			So(json.Unmarshal(mockRenderClient.DoCalls()[0].In2, &payload), ShouldBeNil)
+			So(payload.Data.Items, ShouldHaveLength, 2)
+			So(payload.Data.Items[0].ID, ShouldEqual, "E3")
+			So(payload.Data.Items[1].ID, ShouldEqual, "E4")
+			So(payload.Data.Pagination, ShouldResemble, models.Pagination{
+				CurrentPage: 2,
+				TotalPages:  3,
+				Limit:       2,
+				TotalCount:  5,
+				PreviousURI: "/geography/local-authority?edition=2018&limit=2&page=1",
+				NextURI:     "/geography/local-authority?edition=2018&limit=2&page=3",
+			})
+		})
+
+		Convey("caps the requested limit at the configured maximum", func() {
+			cfg := testConfig()
+			cfg.ListPageMaxLimit = 1
+			req, _ := http.NewRequest("GET", "/geography/local-authority?limit=50", nil)
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+					return codelist.CodesResults{
+						Items: []codelist.Item{{Code: "E1", Label: "A"}, {Code: "E2", Label: "B"}},
+						Count: 2,
+					}, nil
+				},
+			}
+
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(cfg, mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			var payload models.ListPage
+			So(json.Unmarshal(mockRenderClient.DoCalls()[0].In2, &payload), ShouldBeNil)
+			So(payload.Data.Items, ShouldHaveLength, 1)
+			So(payload.Data.Pagination.Limit, ShouldEqual, 1)
+			So(payload.Data.Pagination.TotalPages, ShouldEqual, 2)
+			So(payload.Data.Pagination.PreviousURI, ShouldBeEmpty)
+			So(payload.Data.Pagination.NextURI, ShouldEqual, "/geography/local-authority?limit=50&page=2")
+		})
+
+		Convey("return a 404 status if the requested page is out of range", func() {
+			req, _ := http.NewRequest("GET", "/geography/local-authority?page=3", nil)
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+					return codelist.CodesResults{
+						Items: []codelist.Item{{Code: "E1", Label: "A"}},
+						Count: 1,
+					}, nil
+				},
+			}
+
+			router.Path("/geography/{codeListID}").HandlerFunc(ListPageRender(testConfig(), mockRenderClient, mockCodeListClient))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, 404)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
+		})
 	})
 }
 
@@ -435,7 +878,7 @@ func TestAreaPageRender(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
 			router.ServeHTTP(w, req)
 
 			renderCall := mockRenderClient.DoCalls()[0]
@@ -484,7 +927,9 @@ func TestAreaPageRender(t *testing.T) {
 					return codelist.DatasetsResult{
 						Datasets: []codelist.Dataset{
 							{
-								Links:          codelist.DatasetLinks{},
+								Links: codelist.DatasetLinks{
+									Self: codelist.Link{ID: "mid-year-pop-est"},
+								},
 								DimensionLabal: "Adur",
 								Editions: []codelist.DatasetEdition{
 									{
@@ -513,7 +958,7 @@ func TestAreaPageRender(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(mockRenderClient, mockCodeListClient, mockDatasetClient, "/v1"))
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, "/v1"))
 			router.ServeHTTP(w, req)
 			renderCall := mockRenderClient.DoCalls()[0]
 
@@ -568,6 +1013,36 @@ func TestAreaPageRender(t *testing.T) {
 			})
 		})
 
+		Convey("writes the page model as JSON if the format query parameter is json", func() {
+			req, _ := http.NewRequest("GET", "/geography/local-authority/E07000223?format=json", nil)
+			mockRenderClient := &RenderClientMock{}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodeByIDFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+					return codelist.CodeResult{ID: "E07000223", Label: "Adur"}, nil
+				},
+				GetDatasetsByCodeFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+					return codelist.DatasetsResult{}, nil
+				},
+			}
+			mockDatasetClient := &DatasetClientMock{}
+
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 0)
+
+			var payload models.AreaPage
+			So(json.Unmarshal(w.Body.Bytes(), &payload), ShouldBeNil)
+			So(payload.Metadata.Title, ShouldEqual, "Adur")
+			So(payload.Data.Attributes.Code, ShouldEqual, "E07000223")
+			So(payload.Data.Edition, ShouldEqual, "2018")
+		})
+
 		Convey("return a 500 status if request to GET code-list's editions fails", func() {
 			mockRenderClient := &RenderClientMock{
 				DoFunc: func(path string, bytes []byte) ([]byte, error) {
@@ -591,10 +1066,11 @@ func TestAreaPageRender(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
 			router.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
 
 			Convey("the expected requests are made to the codelist API", func() {
 				editionCalls := mockCodeListClient.GetCodeListEditionsCalls()
@@ -644,10 +1120,11 @@ func TestAreaPageRender(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
 			router.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
 
 			Convey("the expected requests are made to the codelist API", func() {
 				editionCalls := mockCodeListClient.GetCodeListEditionsCalls()
@@ -698,10 +1175,11 @@ func TestAreaPageRender(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
 			router.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
 
 			Convey("the expected requests are made to the codelist API", func() {
 				editionCalls := mockCodeListClient.GetCodeListEditionsCalls()
@@ -747,7 +1225,9 @@ func TestAreaPageRender(t *testing.T) {
 					return codelist.DatasetsResult{
 						Datasets: []codelist.Dataset{
 							{
-								Links:          codelist.DatasetLinks{},
+								Links: codelist.DatasetLinks{
+									Self: codelist.Link{ID: "mid-year-pop-est"},
+								},
 								DimensionLabal: "Adur",
 								Editions: []codelist.DatasetEdition{
 									{
@@ -773,11 +1253,12 @@ func TestAreaPageRender(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
 			router.ServeHTTP(w, req)
 
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 0)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
 
 			Convey("the expected requests are made to the codelist API", func() {
 				editionCalls := mockCodeListClient.GetCodeListEditionsCalls()
@@ -794,6 +1275,197 @@ func TestAreaPageRender(t *testing.T) {
 			})
 		})
 
+		Convey("skips and counts related datasets with no editions", func() {
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			noEditions := testDatasetByCode("cpih01", "time-series")
+			noEditions.Editions = []codelist.DatasetEdition{}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodeByIDFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+					return codelist.CodeResult{Label: "Adur"}, nil
+				},
+				GetDatasetsByCodeFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+					return codelist.DatasetsResult{
+						Datasets: []codelist.Dataset{noEditions, testDatasetByCode("mid-year-pop-est", "time-series")},
+						Count:    2,
+					}, nil
+				},
+			}
+			mockDatasetClient := &DatasetClientMock{
+				GetFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string) (dataset.DatasetDetails, error) {
+					return dataset.DatasetDetails{ID: datasetID, Title: "Population estimates"}, nil
+				},
+			}
+
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(mockDatasetClient.GetCalls(), ShouldHaveLength, 1)
+			So(mockDatasetClient.GetCalls()[0].DatasetID, ShouldEqual, "mid-year-pop-est")
+
+			var payload models.AreaPage
+			So(json.Unmarshal(mockRenderClient.DoCalls()[0].In2, &payload), ShouldBeNil)
+			So(payload.Data.SkippedDatasets, ShouldEqual, 1)
+			So(payload.Data.Datasets, ShouldHaveLength, 1)
+			So(payload.Data.Datasets[0].Label, ShouldEqual, "Population estimates")
+			So(payload.Data.Datasets[0].Editions, ShouldResemble, []models.DatasetEdition{
+				{Edition: "time-series", LatestVersion: "1", URI: "/datasets/mid-year-pop-est/editions/time-series/versions/1"},
+			})
+		})
+
+		Convey("filters the datasets and sorts them by the release date of their latest version", func() {
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodeByIDFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+					return codelist.CodeResult{Label: "Adur"}, nil
+				},
+				GetDatasetsByCodeFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+					return codelist.DatasetsResult{
+						Datasets: []codelist.Dataset{
+							testDatasetByCode("ageing-population-estimates", "time-series"),
+							testDatasetByCode("cpih01", "time-series"),
+							testDatasetByCode("mid-year-pop-est", "time-series"),
+						},
+						Count: 3,
+					}, nil
+				},
+			}
+			releaseDates := map[string]string{
+				"ageing-population-estimates": "2020-06-25T00:00:00.000Z",
+				"cpih01":                      "2022-01-19T07:00:00.000Z",
+				"mid-year-pop-est":            "2021-06-25T00:00:00.000Z",
+			}
+			mockDatasetClient := &DatasetClientMock{
+				GetFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string) (dataset.DatasetDetails, error) {
+					if datasetID == "cpih01" {
+						return dataset.DatasetDetails{ID: datasetID, Title: "Consumer prices index"}, nil
+					}
+					return dataset.DatasetDetails{ID: datasetID, Title: datasetID, Description: "Population estimates"}, nil
+				},
+				GetVersionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, downloadServiceAuthToken string, collectionID string, datasetID string, edition string, version string) (dataset.Version, error) {
+					return dataset.Version{ReleaseDate: releaseDates[datasetID]}, nil
+				},
+			}
+			req := httptest.NewRequest("GET", "/geography/local-authority/E07000223?sort=release_date&q=population", nil)
+
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Last-Modified"), ShouldBeEmpty)
+
+			var payload models.AreaPage
+			So(json.Unmarshal(mockRenderClient.DoCalls()[0].In2, &payload), ShouldBeNil)
+			So(payload.Data.Sort, ShouldEqual, "release_date")
+			So(payload.Data.Query, ShouldEqual, "population")
+			So(payload.Data.Datasets, ShouldHaveLength, 2)
+			So(payload.Data.Datasets[0].DatasetID, ShouldEqual, "mid-year-pop-est")
+			So(payload.Data.Datasets[0].ReleaseDate, ShouldEqual, "2021-06-25T00:00:00.000Z")
+			So(payload.Data.Datasets[1].DatasetID, ShouldEqual, "ageing-population-estimates")
+
+			calls := mockDatasetClient.GetVersionCalls()
+			So(calls, ShouldHaveLength, 3)
+			for _, call := range calls {
+				So(call.Edition, ShouldEqual, "time-series")
+				So(call.Version, ShouldEqual, "1")
+			}
+		})
+
+		Convey("when one of several datasets cannot be retrieved", func() {
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+				GetCodeByIDFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+					return codelist.CodeResult{Label: "Adur"}, nil
+				},
+				GetDatasetsByCodeFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+					return codelist.DatasetsResult{
+						Datasets: []codelist.Dataset{
+							testDatasetByCode("cpih01", "time-series"),
+							testDatasetByCode("mid-year-pop-est", "time-series"),
+							testDatasetByCode("ashe-table-7-earnings", "time-series"),
+						},
+						Count: 3,
+					}, nil
+				},
+			}
+			mockDatasetClient := &DatasetClientMock{
+				GetFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string) (dataset.DatasetDetails, error) {
+					if datasetID == "mid-year-pop-est" {
+						return dataset.DatasetDetails{}, testStatusError(http.StatusServiceUnavailable)
+					}
+					return dataset.DatasetDetails{ID: datasetID, Title: datasetID + " title"}, nil
+				},
+			}
+			cfg := testConfig()
+
+			Convey("the fail policy responds with the status of the dataset error", func() {
+				cfg.AreaPageDatasetFailurePolicy = config.DatasetFailurePolicyFail
+				router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(cfg, mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+				router.ServeHTTP(w, req)
+
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+				So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+				So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
+				So(mockDatasetClient.GetCalls(), ShouldHaveLength, 3)
+			})
+
+			Convey("a dataset that takes longer than the per-call timeout gives a 504 with the fail policy", func() {
+				mockDatasetClient.GetFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string) (dataset.DatasetDetails, error) {
+					if datasetID == "mid-year-pop-est" {
+						<-ctx.Done()
+						return dataset.DatasetDetails{}, ctx.Err()
+					}
+					return dataset.DatasetDetails{ID: datasetID}, nil
+				}
+				cfg.AreaPageDatasetFailurePolicy = config.DatasetFailurePolicyFail
+				cfg.AreaPageCallTimeout = 10 * time.Millisecond
+				router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(cfg, mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+				router.ServeHTTP(w, req)
+
+				So(w.Code, ShouldEqual, http.StatusGatewayTimeout)
+				So(mockDatasetClient.GetCalls(), ShouldHaveLength, 3)
+			})
+
+			Convey("the partial policy renders the datasets that were retrieved, sorted by title, and flags the missing ones", func() {
+				cfg.AreaPageDatasetFailurePolicy = config.DatasetFailurePolicyPartial
+				router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(cfg, mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+				router.ServeHTTP(w, req)
+
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Cache-Control"), ShouldEqual, "no-store")
+				So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+				So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "geography-area")
+
+				var payload models.AreaPage
+				So(json.Unmarshal(mockRenderClient.DoCalls()[0].In2, &payload), ShouldBeNil)
+				So(payload.Data.DatasetsUnavailable, ShouldBeTrue)
+				So(payload.Data.Datasets, ShouldHaveLength, 2)
+				So(payload.Data.Datasets[0].Label, ShouldEqual, "ashe-table-7-earnings title")
+				So(payload.Data.Datasets[1].Label, ShouldEqual, "cpih01 title")
+			})
+		})
+
 		Convey("return a 500 status if rendering service isn't responding", func() {
 			mockRenderClient := &RenderClientMock{
 				DoFunc: func(path string, bytes []byte) ([]byte, error) {
@@ -817,10 +1489,11 @@ func TestAreaPageRender(t *testing.T) {
 				},
 			}
 
-			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
 			router.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, 500)
-			So(len(mockRenderClient.DoCalls()), ShouldEqual, 1)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 2)
+			So(w.Body.String(), ShouldContainSubstring, "Sorry, there is a problem with the service")
 
 			Convey("the expected requests are made to the codelist API", func() {
 				editionCalls := mockCodeListClient.GetCodeListEditionsCalls()
@@ -834,6 +1507,29 @@ func TestAreaPageRender(t *testing.T) {
 				So(datasetsByCodeCalls, ShouldHaveLength, 0)
 			})
 		})
+
+		Convey("return a 404 status if the requested edition does not exist", func() {
+			req, _ := http.NewRequest("GET", "/geography/local-authority/E07000223?edition=1999", nil)
+			mockRenderClient := &RenderClientMock{
+				DoFunc: func(path string, bytes []byte) ([]byte, error) {
+					return bytes, nil
+				},
+			}
+			mockCodeListClient := &CodeListClientMock{
+				GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+					return testEditions, nil
+				},
+			}
+			mockDatasetClient := &DatasetClientMock{}
+
+			router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(AreaPageRender(testConfig(), mockRenderClient, mockCodeListClient, mockDatasetClient, ""))
+			router.ServeHTTP(w, req)
+
+			So(w.Code, ShouldEqual, 404)
+			So(mockRenderClient.DoCalls(), ShouldHaveLength, 1)
+			So(mockRenderClient.DoCalls()[0].In1, ShouldEqual, "error")
+			So(mockCodeListClient.GetCodeByIDCalls(), ShouldBeNil)
+		})
 	})
 }
 
@@ -439,7 +439,8 @@ func (mock *RenderClientMock) DoCalls() []struct {
 }
 
 var (
-	lockDatasetClientMockGet sync.RWMutex
+	lockDatasetClientMockGet        sync.RWMutex
+	lockDatasetClientMockGetVersion sync.RWMutex
 )
 
 // Ensure, that DatasetClientMock does implement DatasetClient.
@@ -455,6 +456,9 @@ var _ DatasetClient = &DatasetClientMock{}
 //             GetFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string) (dataset.DatasetDetails, error) {
 // 	               panic("mock out the Get method")
 //             },
+//             GetVersionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, downloadServiceAuthToken string, collectionID string, datasetID string, edition string, version string) (dataset.Version, error) {
+// 	               panic("mock out the GetVersion method")
+//             },
 //         }
 //
 //         // use mockedDatasetClient in code that requires DatasetClient
@@ -465,6 +469,9 @@ type DatasetClientMock struct {
 	// GetFunc mocks the Get method.
 	GetFunc func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string) (dataset.DatasetDetails, error)
 
+	// GetVersionFunc mocks the GetVersion method.
+	GetVersionFunc func(ctx context.Context, userAuthToken string, serviceAuthToken string, downloadServiceAuthToken string, collectionID string, datasetID string, edition string, version string) (dataset.Version, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// Get holds details about calls to the Get method.
@@ -480,6 +487,25 @@ type DatasetClientMock struct {
 			// DatasetID is the datasetID argument value.
 			DatasetID string
 		}
+		// GetVersion holds details about calls to the GetVersion method.
+		GetVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserAuthToken is the userAuthToken argument value.
+			UserAuthToken string
+			// ServiceAuthToken is the serviceAuthToken argument value.
+			ServiceAuthToken string
+			// DownloadServiceAuthToken is the downloadServiceAuthToken argument value.
+			DownloadServiceAuthToken string
+			// CollectionID is the collectionID argument value.
+			CollectionID string
+			// DatasetID is the datasetID argument value.
+			DatasetID string
+			// Edition is the edition argument value.
+			Edition string
+			// Version is the version argument value.
+			Version string
+		}
 	}
 }
 
@@ -529,3 +555,62 @@ func (mock *DatasetClientMock) GetCalls() []struct {
 	lockDatasetClientMockGet.RUnlock()
 	return calls
 }
+
+// GetVersion calls GetVersionFunc.
+func (mock *DatasetClientMock) GetVersion(ctx context.Context, userAuthToken string, serviceAuthToken string, downloadServiceAuthToken string, collectionID string, datasetID string, edition string, version string) (dataset.Version, error) {
+	if mock.GetVersionFunc == nil {
+		panic("DatasetClientMock.GetVersionFunc: method is nil but DatasetClient.GetVersion was just called")
+	}
+	callInfo := struct {
+		Ctx                      context.Context
+		UserAuthToken            string
+		ServiceAuthToken         string
+		DownloadServiceAuthToken string
+		CollectionID             string
+		DatasetID                string
+		Edition                  string
+		Version                  string
+	}{
+		Ctx:                      ctx,
+		UserAuthToken:            userAuthToken,
+		ServiceAuthToken:         serviceAuthToken,
+		DownloadServiceAuthToken: downloadServiceAuthToken,
+		CollectionID:             collectionID,
+		DatasetID:                datasetID,
+		Edition:                  edition,
+		Version:                  version,
+	}
+	lockDatasetClientMockGetVersion.Lock()
+	mock.calls.GetVersion = append(mock.calls.GetVersion, callInfo)
+	lockDatasetClientMockGetVersion.Unlock()
+	return mock.GetVersionFunc(ctx, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version)
+}
+
+// GetVersionCalls gets all the calls that were made to GetVersion.
+// Check the length with:
+//     len(mockedDatasetClient.GetVersionCalls())
+func (mock *DatasetClientMock) GetVersionCalls() []struct {
+		Ctx                      context.Context
+		UserAuthToken            string
+		ServiceAuthToken         string
+		DownloadServiceAuthToken string
+		CollectionID             string
+		DatasetID                string
+		Edition                  string
+		Version                  string
+} {
+	var calls []struct {
+			Ctx                      context.Context
+			UserAuthToken            string
+			ServiceAuthToken         string
+			DownloadServiceAuthToken string
+			CollectionID             string
+			DatasetID                string
+			Edition                  string
+			Version                  string
+	}
+	lockDatasetClientMockGetVersion.RLock()
+	calls = mock.calls.GetVersion
+	lockDatasetClientMockGetVersion.RUnlock()
+	return calls
+}
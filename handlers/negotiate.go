@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types that the geography routes can respond with
+const (
+	mediaTypeHTML = "text/html"
+	mediaTypeJSON = "application/json"
+	mediaTypeCSV  = "text/csv"
+)
+
+// formats maps the values of the format query parameter to the media type they request
+var formats = map[string]string{
+	"html": mediaTypeHTML,
+	"json": mediaTypeJSON,
+	"csv":  mediaTypeCSV,
+}
+
+// negotiateMediaType returns the media type, out of offers, that the response should be written in. The format
+// query parameter takes precedence over the Accept header. The first offer is the default, used when the request
+// expresses no preference or none of its preferences can be met.
+func negotiateMediaType(req *http.Request, offers ...string) string {
+	if mediaType, ok := formats[req.URL.Query().Get("format")]; ok {
+		for _, offer := range offers {
+			if offer == mediaType {
+				return offer
+			}
+		}
+	}
+
+	best, bestQ := offers[0], 0.0
+	for _, accepted := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		for _, offer := range offers {
+			if q > bestQ && matchesMediaType(mediaType, offer) {
+				best, bestQ = offer, q
+			}
+		}
+	}
+	return best
+}
+
+// matchesMediaType returns true if the accepted media type, which may contain wildcards, matches the offer
+func matchesMediaType(accepted, offer string) bool {
+	if accepted == "*/*" || accepted == offer {
+		return true
+	}
+	return strings.HasSuffix(accepted, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(accepted, "*"))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNegotiateMediaType(t *testing.T) {
+
+	Convey("Given a handler that can respond with HTML or JSON", t, func() {
+		offers := []string{mediaTypeHTML, mediaTypeJSON}
+
+		Convey("HTML is chosen when the request has no preference", func() {
+			req := httptest.NewRequest("GET", "/geography", nil)
+			So(negotiateMediaType(req, offers...), ShouldEqual, mediaTypeHTML)
+		})
+
+		Convey("HTML is chosen for a typical browser Accept header", func() {
+			req := httptest.NewRequest("GET", "/geography", nil)
+			req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+			So(negotiateMediaType(req, offers...), ShouldEqual, mediaTypeHTML)
+		})
+
+		Convey("JSON is chosen when it is the only accepted media type", func() {
+			req := httptest.NewRequest("GET", "/geography", nil)
+			req.Header.Set("Accept", "application/json")
+			So(negotiateMediaType(req, offers...), ShouldEqual, mediaTypeJSON)
+		})
+
+		Convey("JSON is chosen when it has a higher quality than HTML", func() {
+			req := httptest.NewRequest("GET", "/geography", nil)
+			req.Header.Set("Accept", "text/html;q=0.5, application/json")
+			So(negotiateMediaType(req, offers...), ShouldEqual, mediaTypeJSON)
+		})
+
+		Convey("the format query parameter takes precedence over the Accept header", func() {
+			req := httptest.NewRequest("GET", "/geography?format=json", nil)
+			req.Header.Set("Accept", "text/html")
+			So(negotiateMediaType(req, offers...), ShouldEqual, mediaTypeJSON)
+		})
+
+		Convey("an unknown format query parameter is ignored", func() {
+			req := httptest.NewRequest("GET", "/geography?format=xml", nil)
+			So(negotiateMediaType(req, offers...), ShouldEqual, mediaTypeHTML)
+		})
+	})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// UnmatchedRoute is the route of requests that matched no route, or no method of a route, so that metrics and traces
+// label them the same way
+const UnmatchedRoute = "unmatched"
+
+// RouteTemplate returns the path template of the route that req matched, so that every area page is labelled
+// together rather than by its path, or UnmatchedRoute if it matched none
+func RouteTemplate(req *http.Request) string {
+	if r := mux.CurrentRoute(req); r != nil {
+		if template, err := r.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return UnmatchedRoute
+}
+
+// StatusWriter keeps the status written to a response, and passes flushes through so that streamed responses are
+// still streamed
+type StatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// NewStatusWriter wraps w so that the status written to it can be read once the response has been handled
+func NewStatusWriter(w http.ResponseWriter) *StatusWriter {
+	return &StatusWriter{ResponseWriter: w}
+}
+
+// Status returns the status written to the response, which is 200 OK if the handler wrote none
+func (w *StatusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *StatusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *StatusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *StatusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
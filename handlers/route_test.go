@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRouteTemplate(t *testing.T) {
+	Convey("Given a router", t, func() {
+		var route string
+		router := mux.NewRouter()
+		router.Path("/geography/{codeListID}").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			route = RouteTemplate(req)
+		})
+		router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			route = RouteTemplate(req)
+		})
+
+		Convey("a request is labelled with the template of the route it matched", func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/geography/local-authority", nil))
+			So(route, ShouldEqual, "/geography/{codeListID}")
+		})
+
+		Convey("a request that matched no route is labelled as unmatched", func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/unknown", nil))
+			So(route, ShouldEqual, UnmatchedRoute)
+		})
+	})
+}
+
+func TestStatusWriter(t *testing.T) {
+	Convey("Given a status writer", t, func() {
+		w := httptest.NewRecorder()
+		sw := NewStatusWriter(w)
+
+		Convey("the status is 200 OK if none is written", func() {
+			So(sw.Status(), ShouldEqual, http.StatusOK)
+		})
+
+		Convey("the first status written is kept", func() {
+			sw.WriteHeader(http.StatusNotFound)
+			sw.Write([]byte("not found"))
+			So(sw.Status(), ShouldEqual, http.StatusNotFound)
+			So(w.Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("flushes are passed through", func() {
+			sw.Write([]byte("code,label\n"))
+			sw.Flush()
+			So(w.Flushed, ShouldBeTrue)
+		})
+	})
+}
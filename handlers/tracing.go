@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans started by the handlers
+const instrumentationName = "github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+
+// startSpan starts a span as a child of the span in ctx, if there is one. Spans are created by the global tracer
+// provider, which does nothing until tracing is configured.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err on span, if it is not nil, and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// setSpanAttributes adds attributes to the span of the request being handled, such as the code list it is for
+func setSpanAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+}
+
+// startFanOut reports the size of a fan-out made by a handler, and starts a span that the calls it makes are children
+// of. The span must be ended once every call has finished.
+func startFanOut(ctx context.Context, handler string, size int) (context.Context, trace.Span) {
+	observeFanOut(ctx, handler, size)
+	return startSpan(ctx, "fan-out", attribute.String("handler", handler), attribute.Int("size", size))
+}
+
+// render renders a page in a span of its own. The renderer client does not take a context, so the span cannot be
+// passed on to the renderer.
+func render(ctx context.Context, rend RenderClient, templateName string, pageJSON []byte) ([]byte, error) {
+	_, span := startSpan(ctx, "render", attribute.String("template", templateName))
+	pageHTML, err := rend.Do(templateName, pageJSON)
+	EndSpan(span, err)
+	return pageHTML, err
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+const codeListClientName = "codelist"
+
+// CodeListClient is a handlers.CodeListClient that records the duration and errors of each call to the client it wraps
+type CodeListClient struct {
+	client  handlers.CodeListClient
+	metrics *Metrics
+}
+
+// NewCodeListClient wraps the provided client so that its calls are recorded in metrics
+func NewCodeListClient(client handlers.CodeListClient, metrics *Metrics) *CodeListClient {
+	return &CodeListClient{
+		client:  client,
+		metrics: metrics,
+	}
+}
+
+// GetGeographyCodeLists returns the geography code lists
+func (c *CodeListClient) GetGeographyCodeLists(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+	start := time.Now()
+	results, err := c.client.GetGeographyCodeLists(ctx, userAuthToken, serviceAuthToken)
+	c.metrics.observeDownstream(codeListClientName, "GetGeographyCodeLists", start, err)
+	return results, err
+}
+
+// GetCodeListEditions returns the editions of a code list
+func (c *CodeListClient) GetCodeListEditions(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+	start := time.Now()
+	editions, err := c.client.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
+	c.metrics.observeDownstream(codeListClientName, "GetCodeListEditions", start, err)
+	return editions, err
+}
+
+// GetCodes returns the codes of an edition of a code list
+func (c *CodeListClient) GetCodes(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+	start := time.Now()
+	codes, err := c.client.GetCodes(ctx, userAuthToken, serviceAuthToken, codeListID, edition)
+	c.metrics.observeDownstream(codeListClientName, "GetCodes", start, err)
+	return codes, err
+}
+
+// GetCodeByID returns a single code of an edition of a code list
+func (c *CodeListClient) GetCodeByID(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+	start := time.Now()
+	code, err := c.client.GetCodeByID(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+	c.metrics.observeDownstream(codeListClientName, "GetCodeByID", start, err)
+	return code, err
+}
+
+// GetDatasetsByCode returns the datasets related to a code
+func (c *CodeListClient) GetDatasetsByCode(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+	start := time.Now()
+	datasets, err := c.client.GetDatasetsByCode(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+	c.metrics.observeDownstream(codeListClientName, "GetDatasetsByCode", start, err)
+	return datasets, err
+}
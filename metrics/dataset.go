@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/dataset"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+const datasetClientName = "dataset"
+
+// DatasetClient is a handlers.DatasetClient that records the duration and errors of each call to the client it wraps
+type DatasetClient struct {
+	client  handlers.DatasetClient
+	metrics *Metrics
+}
+
+// NewDatasetClient wraps the provided client so that its calls are recorded in metrics
+func NewDatasetClient(client handlers.DatasetClient, metrics *Metrics) *DatasetClient {
+	return &DatasetClient{
+		client:  client,
+		metrics: metrics,
+	}
+}
+
+// Get returns the details of a dataset
+func (c *DatasetClient) Get(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID string) (dataset.DatasetDetails, error) {
+	start := time.Now()
+	details, err := c.client.Get(ctx, userAuthToken, serviceAuthToken, collectionID, datasetID)
+	c.metrics.observeDownstream(datasetClientName, "Get", start, err)
+	return details, err
+}
+
+// GetVersion returns a version of an edition of a dataset
+func (c *DatasetClient) GetVersion(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (dataset.Version, error) {
+	start := time.Now()
+	m, err := c.client.GetVersion(ctx, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version)
+	c.metrics.observeDownstream(datasetClientName, "GetVersion", start, err)
+	return m, err
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "geography"
+
+// Metrics holds the Prometheus metrics of the service, in a registry of its own so that nothing else registered with
+// the default registry is exposed
+type Metrics struct {
+	registry           *prometheus.Registry
+	requests           *prometheus.CounterVec
+	requestDuration    *prometheus.HistogramVec
+	downstreamDuration *prometheus.HistogramVec
+	downstreamErrors   *prometheus.CounterVec
+	fanOutSize         *prometheus.HistogramVec
+	coalescedCalls     *prometheus.CounterVec
+	deduplicatedCalls  *prometheus.CounterVec
+}
+
+// New creates and registers the service's metrics, along with the standard Go runtime and process metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests handled, by route template, method and status.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		downstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "downstream_request_duration_seconds",
+			Help:      "Time taken by calls to the code list API, dataset API and renderer, by client and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"client", "method"}),
+		downstreamErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "downstream_errors_total",
+			Help:      "Number of calls to the code list API, dataset API and renderer that failed, by client, method and status.",
+		}, []string{"client", "method", "status"}),
+		fanOutSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fanout_size",
+			Help:      "Number of downstream calls in each fan-out made to handle a request, by handler.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 11),
+		}, []string{"handler"}),
+		coalescedCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "coalesce",
+			Name:      "calls_total",
+			Help:      "Number of calls made to each coalesced method, by client and method.",
+		}, []string{"client", "method"}),
+		deduplicatedCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "coalesce",
+			Name:      "deduplicated_total",
+			Help:      "Number of calls to each coalesced method that shared a request already in flight, by client and method.",
+		}, []string{"client", "method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.downstreamDuration,
+		m.downstreamErrors,
+		m.fanOutSize,
+		m.coalescedCalls,
+		m.deduplicatedCalls,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware is a mux middleware that counts and times requests by the template of the route they matched, so that
+// every area page is counted together rather than by its path. Requests that matched no route are counted with the
+// route handlers.UnmatchedRoute. It also reports the size of each fan-out made while handling a request.
+func (m *Metrics) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		route := handlers.RouteTemplate(req)
+
+		sw := handlers.NewStatusWriter(w)
+		h.ServeHTTP(sw, req.WithContext(handlers.WithFanOutObserver(req.Context(), m.ObserveFanOut)))
+
+		status := strconv.Itoa(sw.Status())
+		m.requests.WithLabelValues(route, req.Method, status).Inc()
+		m.requestDuration.WithLabelValues(route, req.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// MeasureUnmatched sets the handlers that router uses for requests that match no route, or no method of a route,
+// so that those requests are measured too. mux only applies middleware to requests that matched a route.
+func (m *Metrics) MeasureUnmatched(router *mux.Router) {
+	router.NotFoundHandler = m.Middleware(http.NotFoundHandler())
+	router.MethodNotAllowedHandler = m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+}
+
+// ObserveFanOut records the number of downstream calls in a fan-out made by a handler
+func (m *Metrics) ObserveFanOut(handler string, size int) {
+	m.fanOutSize.WithLabelValues(handler).Observe(float64(size))
+}
+
+// ObserveCoalesced counts a call made to a coalesced method of a client, and whether it was deduplicated by sharing a
+// request that was already in flight
+func (m *Metrics) ObserveCoalesced(client, method string, deduplicated bool) {
+	m.coalescedCalls.WithLabelValues(client, method).Inc()
+	if deduplicated {
+		m.deduplicatedCalls.WithLabelValues(client, method).Inc()
+	}
+}
+
+// observeDownstream records the duration of a call to a downstream client, and counts it as an error if it failed
+// with err. The status of an error is its status code if it has one, or timeout if the call ran out of time.
+func (m *Metrics) observeDownstream(client, method string, start time.Time, err error) {
+	m.downstreamDuration.WithLabelValues(client, method).Observe(time.Since(start).Seconds())
+	if err == nil {
+		return
+	}
+
+	status := "error"
+	var cliErr handlers.ClientError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		status = "timeout"
+	case errors.As(err, &cliErr):
+		status = strconv.Itoa(cliErr.Code())
+	}
+	m.downstreamErrors.WithLabelValues(client, method, status).Inc()
+}
@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/coalesce"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-geography-controller/paging"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testStatusError is an error from a downstream client with a status code
+type testStatusError int
+
+func (e testStatusError) Error() string { return http.StatusText(int(e)) }
+func (e testStatusError) Code() int     { return int(e) }
+
+func TestMiddleware(t *testing.T) {
+
+	Convey("Given a router whose routes are measured", t, func() {
+		m := New()
+		router := mux.NewRouter()
+		router.Use(m.Middleware)
+		m.MeasureUnmatched(router)
+		router.Path("/metrics").Handler(m.Handler())
+		router.Path("/geography/{codeListID}/{codeID}").Methods("GET").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if mux.Vars(req)["codeID"] == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+			w.Write([]byte("<html></html>"))
+		})
+		serveMethod := func(method, target string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+			return w
+		}
+		serve := func(target string) *httptest.ResponseRecorder {
+			return serveMethod("GET", target)
+		}
+
+		Convey("requests are counted by route template and status rather than by path", func() {
+			serve("/geography/local-authority/E06000001")
+			serve("/geography/local-authority/E06000002")
+			serve("/geography/countries/missing")
+
+			route := "/geography/{codeListID}/{codeID}"
+			So(testutil.ToFloat64(m.requests.WithLabelValues(route, "GET", "200")), ShouldEqual, 2)
+			So(testutil.ToFloat64(m.requests.WithLabelValues(route, "GET", "404")), ShouldEqual, 1)
+			So(testutil.CollectAndCount(m.requestDuration), ShouldEqual, 2)
+		})
+
+		Convey("requests that match no route, or no method of a route, are counted as unmatched", func() {
+			So(serve("/wp-login.php").Code, ShouldEqual, http.StatusNotFound)
+			So(serve("/geography/local-authority/E06000001/extra").Code, ShouldEqual, http.StatusNotFound)
+			So(serveMethod("POST", "/geography/local-authority/E06000001").Code, ShouldEqual, http.StatusMethodNotAllowed)
+
+			So(testutil.ToFloat64(m.requests.WithLabelValues("unmatched", "GET", "404")), ShouldEqual, 2)
+			So(testutil.ToFloat64(m.requests.WithLabelValues("unmatched", "POST", "405")), ShouldEqual, 1)
+		})
+
+		Convey("the metrics are exposed in the Prometheus exposition format", func() {
+			serve("/geography/local-authority/E06000001")
+			body := serve("/metrics").Body.String()
+
+			So(body, ShouldContainSubstring, `geography_http_requests_total{method="GET",route="/geography/{codeListID}/{codeID}",status="200"} 1`)
+			So(body, ShouldContainSubstring, "geography_http_request_duration_seconds_bucket")
+			So(body, ShouldContainSubstring, "go_goroutines")
+		})
+
+		Convey("the counts of coalesced calls are exposed", func() {
+			mockCodeListClient := &handlers.CodeListClientMock{
+				GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+					return codelist.CodeListResults{}, nil
+				},
+			}
+			_, err := coalesce.NewCodeListClient(mockCodeListClient, time.Second, m.ObserveCoalesced).GetGeographyCodeLists(context.Background(), "", "")
+			So(err, ShouldBeNil)
+			body := serve("/metrics").Body.String()
+
+			So(body, ShouldContainSubstring, `geography_coalesce_calls_total{client="codelist",method="GetGeographyCodeLists"} 1`)
+		})
+	})
+
+	Convey("Given a measured homepage", t, func() {
+		m := New()
+		mockCodeListClient := &handlers.CodeListClientMock{
+			GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+				return codelist.CodeListResults{Items: []codelist.CodeList{
+					{Links: codelist.CodeListLinks{Self: &codelist.Link{ID: "local-authority"}}},
+					{Links: codelist.CodeListLinks{Self: &codelist.Link{ID: "countries"}}},
+				}}, nil
+			},
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				return codelist.EditionsListResults{}, nil
+			},
+		}
+		mockRenderClient := &handlers.RenderClientMock{
+			DoFunc: func(templateName string, page []byte) ([]byte, error) {
+				return []byte("<html></html>"), nil
+			},
+		}
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+
+		router := mux.NewRouter()
+		router.Use(m.Middleware)
+		router.Path("/geography").HandlerFunc(handlers.HomepageRender(*cfg, NewRenderClient(mockRenderClient, m), NewCodeListClient(mockCodeListClient, m)))
+
+		Convey("its fan-out size and downstream calls are recorded", func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/geography", nil))
+
+			So(testutil.CollectAndCount(m.fanOutSize), ShouldEqual, 1)
+			So(testutil.CollectAndCount(m.downstreamDuration), ShouldEqual, 3)
+			So(testutil.CollectAndCount(m.downstreamErrors), ShouldEqual, 0)
+
+			expected := `
+# HELP geography_fanout_size Number of downstream calls in each fan-out made to handle a request, by handler.
+# TYPE geography_fanout_size histogram
+geography_fanout_size_bucket{handler="homepage",le="1"} 0
+geography_fanout_size_bucket{handler="homepage",le="2"} 1
+geography_fanout_size_bucket{handler="homepage",le="4"} 1
+geography_fanout_size_bucket{handler="homepage",le="8"} 1
+geography_fanout_size_bucket{handler="homepage",le="16"} 1
+geography_fanout_size_bucket{handler="homepage",le="32"} 1
+geography_fanout_size_bucket{handler="homepage",le="64"} 1
+geography_fanout_size_bucket{handler="homepage",le="128"} 1
+geography_fanout_size_bucket{handler="homepage",le="256"} 1
+geography_fanout_size_bucket{handler="homepage",le="512"} 1
+geography_fanout_size_bucket{handler="homepage",le="1024"} 1
+geography_fanout_size_bucket{handler="homepage",le="+Inf"} 1
+geography_fanout_size_sum{handler="homepage"} 2
+geography_fanout_size_count{handler="homepage"} 1
+`
+			So(testutil.CollectAndCompare(m.fanOutSize, strings.NewReader(expected)), ShouldBeNil)
+		})
+	})
+}
+
+func TestDownstream(t *testing.T) {
+
+	Convey("Given a measured code list client", t, func() {
+		m := New()
+		mockCodeListClient := &handlers.CodeListClientMock{
+			GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				switch codeListID {
+				case "missing":
+					return codelist.CodesResults{}, testStatusError(http.StatusNotFound)
+				case "slow":
+					return codelist.CodesResults{}, context.DeadlineExceeded
+				case "broken":
+					return codelist.CodesResults{}, errors.New("connection refused")
+				}
+				return codelist.CodesResults{}, nil
+			},
+		}
+		c := NewCodeListClient(mockCodeListClient, m)
+
+		Convey("each call is timed by method, and failed calls are counted by status", func() {
+			for _, codeListID := range []string{"local-authority", "missing", "missing", "slow", "broken"} {
+				c.GetCodes(context.Background(), "", "", codeListID, "2019")
+			}
+
+			So(testutil.CollectAndCount(m.downstreamDuration), ShouldEqual, 1)
+			So(testutil.ToFloat64(m.downstreamErrors.WithLabelValues("codelist", "GetCodes", "404")), ShouldEqual, 2)
+			So(testutil.ToFloat64(m.downstreamErrors.WithLabelValues("codelist", "GetCodes", "timeout")), ShouldEqual, 1)
+			So(testutil.ToFloat64(m.downstreamErrors.WithLabelValues("codelist", "GetCodes", "error")), ShouldEqual, 1)
+		})
+	})
+
+	Convey("Given a paging code list client over a measured page client", t, func() {
+		m := New()
+		mockPages := &paging.PageClientMock{
+			GetCodesPageFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, offset int, limit int) (codelist.CodesResults, error) {
+				return codelist.CodesResults{Items: make([]codelist.Item, limit), Count: limit, TotalCount: 3 * limit}, nil
+			},
+		}
+		c := paging.NewCodeListClient(&handlers.CodeListClientMock{}, NewPageClient(mockPages, m), 2, 1)
+
+		Convey("each page request is recorded", func() {
+			_, err := c.GetCodes(context.Background(), "", "", "local-authority", "2019")
+			So(err, ShouldBeNil)
+
+			w := httptest.NewRecorder()
+			m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+			So(mockPages.GetCodesPageCalls(), ShouldHaveLength, 3)
+			So(w.Body.String(), ShouldContainSubstring, `geography_downstream_request_duration_seconds_count{client="codelist",method="GetCodesPage"} 3`)
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/paging"
+)
+
+// PageClient is a paging.PageClient that records the duration and errors of each request for a single page, so that
+// every request made to the code list api for a paginated response is recorded
+type PageClient struct {
+	client  paging.PageClient
+	metrics *Metrics
+}
+
+// NewPageClient wraps the provided client so that its page requests are recorded in metrics
+func NewPageClient(client paging.PageClient, metrics *Metrics) *PageClient {
+	return &PageClient{
+		client:  client,
+		metrics: metrics,
+	}
+}
+
+// GetGeographyCodeListsPage returns a page of the geography code lists
+func (c *PageClient) GetGeographyCodeListsPage(ctx context.Context, userAuthToken, serviceAuthToken string, offset, limit int) (codelist.CodeListResults, error) {
+	start := time.Now()
+	results, err := c.client.GetGeographyCodeListsPage(ctx, userAuthToken, serviceAuthToken, offset, limit)
+	c.metrics.observeDownstream(codeListClientName, "GetGeographyCodeListsPage", start, err)
+	return results, err
+}
+
+// GetCodesPage returns a page of the codes of an edition of a code list
+func (c *PageClient) GetCodesPage(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition string, offset, limit int) (codelist.CodesResults, error) {
+	start := time.Now()
+	codes, err := c.client.GetCodesPage(ctx, userAuthToken, serviceAuthToken, codeListID, edition, offset, limit)
+	c.metrics.observeDownstream(codeListClientName, "GetCodesPage", start, err)
+	return codes, err
+}
+
+// GetDatasetsByCodePage returns a page of the datasets related to a code
+func (c *PageClient) GetDatasetsByCodePage(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition, codeID string, offset, limit int) (codelist.DatasetsResult, error) {
+	start := time.Now()
+	datasets, err := c.client.GetDatasetsByCodePage(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID, offset, limit)
+	c.metrics.observeDownstream(codeListClientName, "GetDatasetsByCodePage", start, err)
+	return datasets, err
+}
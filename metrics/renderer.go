@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+const renderClientName = "renderer"
+
+// RenderClient is a handlers.RenderClient that records the duration and errors of each call to the client it wraps
+type RenderClient struct {
+	client  handlers.RenderClient
+	metrics *Metrics
+}
+
+// NewRenderClient wraps the provided client so that its calls are recorded in metrics
+func NewRenderClient(client handlers.RenderClient, metrics *Metrics) *RenderClient {
+	return &RenderClient{
+		client:  client,
+		metrics: metrics,
+	}
+}
+
+// Do renders a template with the provided page model
+func (c *RenderClient) Do(templateName string, page []byte) ([]byte, error) {
+	start := time.Now()
+	html, err := c.client.Do(templateName, page)
+	c.metrics.observeDownstream(renderClientName, "Do", start, err)
+	return html, err
+}
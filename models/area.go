@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/ONSdigital/dp-frontend-models/model"
+	"github.com/ONSdigital/dp-frontend-models/model/geography/area"
+)
+
+// AreaPage represents the template data structure used for the geography area page
+type AreaPage struct {
+	model.Page
+	Data AreaPageData `json:"data"`
+}
+
+// AreaPageData extends the area page data with the editions of the code list the area belongs to, and the datasets
+// related to the area with each of their editions. DatasetsUnavailable is set when some of the datasets related to
+// the area could not be retrieved and are missing from the page, and SkippedDatasets counts the related datasets left
+// off the page because the code list API entry for them is malformed. Sort and Query are the order and free text
+// filter the datasets are shown with.
+type AreaPageData struct {
+	area.GeographyAreaPage
+	Datasets            []Dataset `json:"items"`
+	Edition             string    `json:"edition"`
+	Editions            []Edition `json:"editions"`
+	DatasetsUnavailable bool      `json:"datasets_unavailable"`
+	SkippedDatasets     int       `json:"skipped_datasets"`
+	Sort                string    `json:"sort"`
+	Query               string    `json:"q"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "github.com/ONSdigital/dp-frontend-models/model/geography/area"
+
+// Dataset extends a dataset related to an area with its dataset ID and every edition of the dataset that covers the
+// area. ReleaseDate is the release date of the latest version of the linked edition, which is only set when the
+// datasets are sorted by release date.
+type Dataset struct {
+	area.Dataset
+	DatasetID   string           `json:"dataset_id"`
+	ReleaseDate string           `json:"release_date,omitempty"`
+	Editions    []DatasetEdition `json:"editions"`
+}
+
+// DatasetEdition represents an edition of a dataset related to an area, linking to its latest version
+type DatasetEdition struct {
+	Edition       string `json:"edition"`
+	LatestVersion string `json:"latest_version"`
+	URI           string `json:"uri"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Edition represents an edition of a code list that a user can switch to
+type Edition struct {
+	Edition  string `json:"edition"`
+	Label    string `json:"label"`
+	URI      string `json:"uri"`
+	Selected bool   `json:"selected"`
+}
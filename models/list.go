@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/ONSdigital/dp-frontend-models/model"
+	"github.com/ONSdigital/dp-frontend-models/model/geography/list"
+)
+
+// ListPage represents the template data structure used for the geography list page
+type ListPage struct {
+	model.Page
+	Data ListPageData `json:"data"`
+}
+
+// ListPageData extends the list page data with the editions of the code list being shown and the
+// position of the page within the codes of that edition
+type ListPageData struct {
+	list.GeographyListPage
+	Edition    string     `json:"edition"`
+	Editions   []Edition  `json:"editions"`
+	Pagination Pagination `json:"pagination"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// Pagination represents the position of the current page within a paginated list
+type Pagination struct {
+	CurrentPage int    `json:"current_page"`
+	TotalPages  int    `json:"total_pages"`
+	Limit       int    `json:"limit"`
+	TotalCount  int    `json:"total_count"`
+	PreviousURI string `json:"previous_uri,omitempty"`
+	NextURI     string `json:"next_uri,omitempty"`
+}
@@ -0,0 +1,323 @@
+package pagecache
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-cookies/cookies"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	dprequest "github.com/ONSdigital/dp-net/request"
+)
+
+// storedHeaders are the response headers kept with a cached page. Cache-Control and Vary are left out, as they are
+// set around the page cache for every response.
+var storedHeaders = []string{"Content-Type", "ETag"}
+
+// Cache is an in-memory cache of rendered HTML pages, so that repeated requests for the same page are answered
+// without calling the renderer. Pages expire after a fixed TTL, and once the pages held take up more than maxBytes
+// the least recently used page is evicted to make room.
+type Cache struct {
+	ttl      time.Duration
+	maxBytes int
+	mutex    sync.Mutex
+	size     int
+	items    map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+	hits     int
+	misses   int
+}
+
+// Stats describes the configuration and use of a Cache
+type Stats struct {
+	TTL      string `json:"ttl"`
+	MaxBytes int    `json:"max_bytes"`
+	Pages    int    `json:"pages"`
+	Bytes    int    `json:"bytes"`
+	Hits     int    `json:"hits"`
+	Misses   int    `json:"misses"`
+}
+
+type page struct {
+	key     string
+	path    string
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// size approximates the memory used by a page, which is dominated by its body
+func (p *page) size() int {
+	n := len(p.key) + len(p.body)
+	for k, values := range p.header {
+		n += len(k)
+		for _, v := range values {
+			n += len(v)
+		}
+	}
+	return n
+}
+
+// New creates a Cache with the provided TTL and maximum size in bytes. A TTL or maxBytes of zero or less disables
+// the cache.
+func New(ttl time.Duration, maxBytes int) *Cache {
+	return &Cache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Enabled returns true if the cache is able to hold any pages
+func (c *Cache) Enabled() bool {
+	return c.ttl > 0 && c.maxBytes > 0
+}
+
+// Middleware wraps a page handler so that its successful HTML responses are cached and served from the cache.
+// Previews are never cached, and conditional requests always reach the handler, which answers them without
+// rendering the page.
+func (c *Cache) Middleware(h http.HandlerFunc) http.HandlerFunc {
+	if !c.Enabled() {
+		return h
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet || handlers.IsPreviewRequest(req) || isConditional(req) {
+			h(w, req)
+			return
+		}
+
+		key := pageKey(req)
+		if p, ok := c.get(key); ok {
+			for k, values := range p.header {
+				w.Header()[k] = values
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write(p.body)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK, limit: c.maxBytes}
+		h(rec, req)
+		if rec.cacheable() {
+			header := http.Header{}
+			for _, k := range storedHeaders {
+				if v := w.Header().Get(k); v != "" {
+					header.Set(k, v)
+				}
+			}
+			c.set(&page{key: key, path: req.URL.Path, header: header, body: rec.body.Bytes()})
+		}
+	}
+}
+
+// Purge removes every page whose path starts with prefix, or every page if prefix is empty, and returns the number
+// of pages removed
+func (c *Cache) Purge(prefix string) int {
+	return c.PurgeMatching(func(path string) bool {
+		return strings.HasPrefix(path, prefix)
+	})
+}
+
+// PurgeMatching removes every page whose path matches, and returns the number of pages removed
+func (c *Cache) PurgeMatching(match func(path string) bool) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	purged := 0
+	for _, elem := range c.items {
+		if match(elem.Value.(*page).path) {
+			c.removeElement(elem)
+			purged++
+		}
+	}
+	return purged
+}
+
+// PurgeCodeList removes every page that shows a code list: the homepage, which lists it, and its list and area
+// pages. It returns the number of pages removed.
+func (c *Cache) PurgeCodeList(codeListID string) int {
+	prefix := "/geography/" + codeListID
+	return c.PurgeMatching(func(path string) bool {
+		return path == "/geography" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	})
+}
+
+// PurgeArea removes every cached area page for a code, and returns the number of pages removed
+func (c *Cache) PurgeArea(codeListID, codeID string) int {
+	path := "/geography/" + codeListID + "/" + codeID
+	return c.PurgeMatching(func(p string) bool {
+		return p == path
+	})
+}
+
+// Keys returns a readable form of the keys of the pages currently held, from the most to the least recently used.
+// Each is the path and query followed by the language, cookie preferences and media type the page was rendered for.
+func (c *Cache) Keys() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		parts := strings.Split(elem.Value.(*page).key, "\x00")
+		if parts[1] != "" {
+			parts[0] += "?" + parts[1]
+		}
+		keys = append(keys, strings.Join(append(parts[:1], parts[2:]...), " "))
+	}
+	return keys
+}
+
+// Stats returns the configuration of the cache, the pages it holds, and the number of requests served from it or
+// passed on to the page handler because the page was not held since it was created
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return Stats{
+		TTL:      c.ttl.String(),
+		MaxBytes: c.maxBytes,
+		Pages:    c.order.Len(),
+		Bytes:    c.size,
+		Hits:     c.hits,
+		Misses:   c.misses,
+	}
+}
+
+// Len returns the number of pages currently held, including any that have expired but not yet been removed
+func (c *Cache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+// Size returns the approximate number of bytes taken up by the pages currently held
+func (c *Cache) Size() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.size
+}
+
+func (c *Cache) get(key string) (*page, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	p := elem.Value.(*page)
+	if !c.now().Before(p.expires) {
+		c.removeElement(elem)
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return p, true
+}
+
+func (c *Cache) set(p *page) {
+	size := p.size()
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.items[p.key]; ok {
+		c.removeElement(elem)
+	}
+	p.expires = c.now().Add(c.ttl)
+	c.items[p.key] = c.order.PushFront(p)
+	c.size += size
+	for c.size > c.maxBytes {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *Cache) removeElement(elem *list.Element) {
+	p := elem.Value.(*page)
+	c.order.Remove(elem)
+	delete(c.items, p.key)
+	c.size -= p.size()
+}
+
+// pageKey identifies everything a page is rendered from: the path, which holds the route variables, the query, which
+// holds the edition, page, sort and filter, the language, the cookie preferences and the media type asked for
+func pageKey(req *http.Request) string {
+	preferences := cookies.GetCookiePreferences(req)
+	return strings.Join([]string{
+		req.URL.Path,
+		req.URL.Query().Encode(),
+		dprequest.GetLocaleCode(req),
+		fmt.Sprintf("%t,%t,%t", preferences.IsPreferenceSet, preferences.Policy.Essential, preferences.Policy.Usage),
+		req.Header.Get("Accept"),
+	}, "\x00")
+}
+
+// isConditional returns true if the request has preconditions that could be answered with 304 Not Modified. Pages
+// have no Last-Modified time, so only If-None-Match is a precondition.
+func isConditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != ""
+}
+
+// recorder passes a response through to the client while keeping a copy of its status and body, up to limit bytes.
+// Flushes are passed through too, so that streamed responses such as CSV downloads are still streamed.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	limit       int
+	wroteHeader bool
+	overflowed  bool
+	flushed     bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.overflowed {
+		if r.body.Len()+len(b) > r.limit {
+			r.overflowed = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush sends what has been written so far to the client. A flushed response is streamed, so it is not cached.
+func (r *recorder) Flush() {
+	r.flushed = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// cacheable returns true if the response was a complete, successful HTML page that was not streamed, and that the
+// handler did not mark as one that must not be stored
+func (r *recorder) cacheable() bool {
+	return r.status == http.StatusOK && !r.overflowed && !r.flushed &&
+		strings.HasPrefix(r.ResponseWriter.Header().Get("Content-Type"), "text/html") &&
+		!strings.Contains(r.ResponseWriter.Header().Get("Cache-Control"), "no-store")
+}
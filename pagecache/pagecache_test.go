@@ -0,0 +1,199 @@
+package pagecache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMiddleware(t *testing.T) {
+
+	Convey("Given a page handler behind a page cache", t, func() {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		c := New(time.Minute, 1<<20)
+		c.now = func() time.Time { return now }
+
+		calls := 0
+		status := http.StatusOK
+		contentType := "text/html; charset=utf-8"
+		cacheControl := ""
+		h := c.Middleware(func(w http.ResponseWriter, req *http.Request) {
+			calls++
+			if cacheControl != "" {
+				w.Header().Set("Cache-Control", cacheControl)
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("ETag", `"abc"`)
+			w.WriteHeader(status)
+			w.Write([]byte("<html>" + req.URL.Path + "</html>"))
+		})
+		serve := func(req *http.Request) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			h(w, req)
+			return w
+		}
+
+		Convey("a repeated request is served from the cache with the same headers", func() {
+			first := serve(httptest.NewRequest("GET", "/geography/local-authority", nil))
+			second := serve(httptest.NewRequest("GET", "/geography/local-authority", nil))
+
+			So(calls, ShouldEqual, 1)
+			So(second.Code, ShouldEqual, http.StatusOK)
+			So(second.Body.String(), ShouldEqual, first.Body.String())
+			So(second.Header().Get("Content-Type"), ShouldEqual, "text/html; charset=utf-8")
+			So(second.Header().Get("ETag"), ShouldEqual, `"abc"`)
+			So(c.Len(), ShouldEqual, 1)
+		})
+
+		Convey("requests for a different edition, language or cookie preference are cached separately", func() {
+			serve(httptest.NewRequest("GET", "/geography/local-authority", nil))
+			serve(httptest.NewRequest("GET", "/geography/local-authority?edition=2019", nil))
+
+			welsh := httptest.NewRequest("GET", "/geography/local-authority", nil)
+			welsh.AddCookie(&http.Cookie{Name: "lang", Value: "cy"})
+			serve(welsh)
+
+			accepted := httptest.NewRequest("GET", "/geography/local-authority", nil)
+			accepted.AddCookie(&http.Cookie{Name: "cookies_preferences_set", Value: "true"})
+			serve(accepted)
+
+			So(calls, ShouldEqual, 4)
+			So(c.Len(), ShouldEqual, 4)
+		})
+
+		Convey("previews are never cached", func() {
+			req := httptest.NewRequest("GET", "/geography/local-authority", nil)
+			req.Header.Set("X-Florence-Token", "florence-token")
+			serve(req)
+			serve(req)
+			So(calls, ShouldEqual, 2)
+			So(c.Len(), ShouldEqual, 0)
+		})
+
+		Convey("conditional requests always reach the handler", func() {
+			serve(httptest.NewRequest("GET", "/geography/local-authority", nil))
+			req := httptest.NewRequest("GET", "/geography/local-authority", nil)
+			req.Header.Set("If-None-Match", `"abc"`)
+			serve(req)
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("error pages and responses that are not HTML are not cached", func() {
+			status = http.StatusNotFound
+			serve(httptest.NewRequest("GET", "/geography/unknown", nil))
+			status = http.StatusOK
+			contentType = "application/json; charset=utf-8"
+			serve(httptest.NewRequest("GET", "/geography/local-authority?format=json", nil))
+			So(c.Len(), ShouldEqual, 0)
+		})
+
+		Convey("pages that must not be stored, such as partial pages, are not cached", func() {
+			cacheControl = "no-store"
+			serve(httptest.NewRequest("GET", "/geography", nil))
+			second := serve(httptest.NewRequest("GET", "/geography", nil))
+			So(calls, ShouldEqual, 2)
+			So(second.Code, ShouldEqual, http.StatusOK)
+			So(c.Len(), ShouldEqual, 0)
+		})
+
+		Convey("pages expire after the TTL", func() {
+			serve(httptest.NewRequest("GET", "/geography/local-authority", nil))
+			now = now.Add(time.Minute)
+			serve(httptest.NewRequest("GET", "/geography/local-authority", nil))
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("pages can be purged by path prefix", func() {
+			serve(httptest.NewRequest("GET", "/geography/local-authority", nil))
+			serve(httptest.NewRequest("GET", "/geography/local-authority/E07000223", nil))
+			serve(httptest.NewRequest("GET", "/geography/countries", nil))
+
+			So(c.Purge("/geography/local-authority"), ShouldEqual, 2)
+			So(c.Len(), ShouldEqual, 1)
+
+			Convey("or all at once through the purge handler", func() {
+				w := httptest.NewRecorder()
+				c.PurgeHandler(w, httptest.NewRequest("DELETE", "/admin/page-cache", nil))
+
+				var body map[string]int
+				So(json.Unmarshal(w.Body.Bytes(), &body), ShouldBeNil)
+				So(body["purged"], ShouldEqual, 1)
+				So(c.Len(), ShouldEqual, 0)
+				So(c.Size(), ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given a CSV download that streams its rows behind a page cache", t, func() {
+		c := New(time.Minute, 1<<20)
+		calls := 0
+		var client *httptest.ResponseRecorder
+		var received []string
+		h := c.Middleware(func(w http.ResponseWriter, req *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			flusher, ok := w.(http.Flusher)
+			So(ok, ShouldBeTrue)
+			for _, row := range []string{"code,label\n", "E07000223,Adur\n"} {
+				w.Write([]byte(row))
+				flusher.Flush()
+				So(client.Flushed, ShouldBeTrue)
+				received = append(received, client.Body.String())
+			}
+		})
+
+		Convey("each row reaches the client when it is flushed, and the download is not cached", func() {
+			for _, accept := range []string{"", "text/csv"} {
+				req := httptest.NewRequest("GET", "/geography/local-authority?format=csv", nil)
+				req.Header.Set("Accept", accept)
+				client = httptest.NewRecorder()
+				h(client, req)
+			}
+			So(received, ShouldResemble, []string{
+				"code,label\n", "code,label\nE07000223,Adur\n",
+				"code,label\n", "code,label\nE07000223,Adur\n",
+			})
+			So(calls, ShouldEqual, 2)
+			So(c.Len(), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given a page cache that can hold about two pages", t, func() {
+		c := New(time.Minute, 400)
+		body := strings.Repeat("x", 100)
+		h := c.Middleware(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(body))
+		})
+		serve := func(path string) {
+			h(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+
+		Convey("the least recently used page is evicted to stay within the size", func() {
+			serve("/geography/a")
+			serve("/geography/b")
+			serve("/geography/c")
+			So(c.Len(), ShouldEqual, 2)
+			So(c.Size(), ShouldBeLessThanOrEqualTo, 400)
+		})
+
+		Convey("pages larger than the whole cache are not stored", func() {
+			body = strings.Repeat("x", 500)
+			serve("/geography/a")
+			So(c.Len(), ShouldEqual, 0)
+		})
+	})
+
+	Convey("A disabled page cache leaves the handler unwrapped", t, func() {
+		calls := 0
+		h := New(0, 0).Middleware(func(w http.ResponseWriter, req *http.Request) { calls++ })
+		h(httptest.NewRecorder(), httptest.NewRequest("GET", "/geography", nil))
+		h(httptest.NewRecorder(), httptest.NewRequest("GET", "/geography", nil))
+		So(calls, ShouldEqual, 2)
+	})
+}
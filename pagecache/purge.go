@@ -0,0 +1,19 @@
+package pagecache
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// PurgeHandler removes cached pages, either every page or, with the path query parameter, those whose path starts
+// with it. It responds with the number of pages removed.
+func (c *Cache) PurgeHandler(w http.ResponseWriter, req *http.Request) {
+	prefix := req.URL.Query().Get("path")
+	purged := c.Purge(prefix)
+	log.Info(req.Context(), "purged page cache", log.Data{"path": prefix, "purged": purged})
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(map[string]int{"purged": purged})
+}
@@ -0,0 +1,110 @@
+package paging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-api-clients-go/headers"
+	"github.com/ONSdigital/dp-api-clients-go/health"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// ErrInvalidResponse is returned when the code list api does not respond with a 200 status
+type ErrInvalidResponse struct {
+	actualCode int
+	uri        string
+}
+
+func (e ErrInvalidResponse) Error() string {
+	return fmt.Sprintf("invalid response from codelist api - should be: %d, got: %d, path: %s", http.StatusOK, e.actualCode, e.uri)
+}
+
+// Code returns the status code received from the code list api
+func (e ErrInvalidResponse) Code() int {
+	return e.actualCode
+}
+
+// Client requests individual pages of the paginated code list api endpoints. IDs are escaped before they are added
+// to the path, so that they cannot change which endpoint is requested.
+type Client struct {
+	hcCli *health.Client
+}
+
+// NewClient creates a Client reusing the URL and Clienter of the provided health client
+func NewClient(hcCli *health.Client) *Client {
+	return &Client{hcCli: hcCli}
+}
+
+// GetGeographyCodeListsPage returns a page of the geography code lists
+func (c *Client) GetGeographyCodeListsPage(ctx context.Context, userAuthToken, serviceAuthToken string, offset, limit int) (codelist.CodeListResults, error) {
+	var results codelist.CodeListResults
+	uri := fmt.Sprintf("%s/code-lists?type=geography&%s", c.hcCli.URL, pageQuery(offset, limit))
+	err := c.get(ctx, userAuthToken, serviceAuthToken, uri, &results)
+	return results, err
+}
+
+// GetCodesPage returns a page of the codes of an edition of a code list
+func (c *Client) GetCodesPage(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition string, offset, limit int) (codelist.CodesResults, error) {
+	var results codelist.CodesResults
+	uri := fmt.Sprintf("%s/code-lists/%s/editions/%s/codes?%s", c.hcCli.URL, url.PathEscape(codeListID), url.PathEscape(edition), pageQuery(offset, limit))
+	err := c.get(ctx, userAuthToken, serviceAuthToken, uri, &results)
+	return results, err
+}
+
+// GetDatasetsByCodePage returns a page of the datasets related to a code
+func (c *Client) GetDatasetsByCodePage(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition, codeID string, offset, limit int) (codelist.DatasetsResult, error) {
+	var results codelist.DatasetsResult
+	uri := fmt.Sprintf("%s/code-lists/%s/editions/%s/codes/%s/datasets?%s", c.hcCli.URL, url.PathEscape(codeListID), url.PathEscape(edition), url.PathEscape(codeID), pageQuery(offset, limit))
+	err := c.get(ctx, userAuthToken, serviceAuthToken, uri, &results)
+	return results, err
+}
+
+func (c *Client) get(ctx context.Context, userAuthToken, serviceAuthToken, uri string, v interface{}) error {
+	log.Info(ctx, "retrieving page from code list api", log.Data{"uri": uri})
+
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	if err = headers.SetUserAuthToken(req, userAuthToken); err != nil && err != headers.ErrValueEmpty {
+		return err
+	}
+	if err = headers.SetServiceAuthToken(req, serviceAuthToken); err != nil && err != headers.ErrValueEmpty {
+		return err
+	}
+
+	resp, err := c.hcCli.Client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if resp.Body == nil {
+			return
+		}
+		if err := resp.Body.Close(); err != nil {
+			log.Error(ctx, "error closing http response body", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrInvalidResponse{actualCode: resp.StatusCode, uri: uri}
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func pageQuery(offset, limit int) string {
+	query := url.Values{}
+	query.Set("offset", fmt.Sprint(offset))
+	query.Set("limit", fmt.Sprint(limit))
+	return query.Encode()
+}
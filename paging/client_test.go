@@ -0,0 +1,78 @@
+package paging
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/headers"
+	"github.com/ONSdigital/dp-api-clients-go/health"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newMockHTTPClient(status int, body string) *dphttp.ClienterMock {
+	return &dphttp.ClienterMock{
+		SetPathsWithNoRetriesFunc: func(paths []string) {},
+		GetPathsWithNoRetriesFunc: func() []string { return []string{} },
+		DoFunc: func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			}, nil
+		},
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a page client and a code list api that responds successfully", t, func() {
+		httpClient := newMockHTTPClient(http.StatusOK, `{"items":[{"code":"E07000223","label":"Adur"}],"count":1,"offset":2,"limit":1,"total_count":3}`)
+		cli := NewClient(health.NewClientWithClienter("api-router", "http://localhost:23200/v1", httpClient))
+
+		Convey("the requested page is returned", func() {
+			codes, err := cli.GetCodesPage(ctx, "user", "service", "local-authority", "2018", 2, 1)
+			So(err, ShouldBeNil)
+			So(codes.Items, ShouldHaveLength, 1)
+			So(codes.TotalCount, ShouldEqual, 3)
+
+			calls := httpClient.DoCalls()
+			So(calls, ShouldHaveLength, 1)
+			So(calls[0].Req.URL.String(), ShouldEqual, "http://localhost:23200/v1/code-lists/local-authority/editions/2018/codes?limit=1&offset=2")
+			userAuthToken, _ := headers.GetUserAuthToken(calls[0].Req)
+			So(userAuthToken, ShouldEqual, "user")
+			serviceAuthToken, _ := headers.GetServiceAuthToken(calls[0].Req)
+			So(serviceAuthToken, ShouldEqual, "service")
+		})
+	})
+
+	Convey("Given a page client and IDs with characters that are reserved in a URL", t, func() {
+		httpClient := newMockHTTPClient(http.StatusOK, `{"items":[],"count":0,"offset":0,"limit":10,"total_count":0}`)
+		cli := NewClient(health.NewClientWithClienter("api-router", "http://localhost:23200/v1", httpClient))
+
+		Convey("each ID is escaped as a single path segment", func() {
+			_, err := cli.GetDatasetsByCodePage(ctx, "", "", "local/authority", "2018?edition=2019", "50%", 0, 10)
+			So(err, ShouldBeNil)
+
+			calls := httpClient.DoCalls()
+			So(calls, ShouldHaveLength, 1)
+			So(calls[0].Req.URL.String(), ShouldEqual, "http://localhost:23200/v1/code-lists/local%2Fauthority/editions/2018%3Fedition=2019/codes/50%25/datasets?limit=10&offset=0")
+			So(calls[0].Req.URL.Path, ShouldEqual, "/v1/code-lists/local/authority/editions/2018?edition=2019/codes/50%/datasets")
+			So(calls[0].Req.URL.Query(), ShouldHaveLength, 2)
+		})
+	})
+
+	Convey("Given a page client and a code list api that responds with a 404", t, func() {
+		httpClient := newMockHTTPClient(http.StatusNotFound, "")
+		cli := NewClient(health.NewClientWithClienter("api-router", "http://localhost:23200/v1", httpClient))
+
+		Convey("an error with the status code is returned", func() {
+			_, err := cli.GetGeographyCodeListsPage(ctx, "", "", 0, 10)
+			So(err, ShouldNotBeNil)
+			So(err.(ErrInvalidResponse).Code(), ShouldEqual, http.StatusNotFound)
+		})
+	})
+}
@@ -0,0 +1,163 @@
+package paging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+//go:generate moq -out mocks_paging.go . PageClient
+
+// PageClient is an interface with methods required to request individual pages of the code list api endpoints
+type PageClient interface {
+	GetGeographyCodeListsPage(ctx context.Context, userAuthToken, serviceAuthToken string, offset, limit int) (codelist.CodeListResults, error)
+	GetCodesPage(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition string, offset, limit int) (codelist.CodesResults, error)
+	GetDatasetsByCodePage(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition, codeID string, offset, limit int) (codelist.DatasetsResult, error)
+}
+
+// ErrInconsistentTotal is returned when the pages of a paginated response disagree about the total number of items
+type ErrInconsistentTotal struct {
+	expected int
+	actual   int
+}
+
+func (e ErrInconsistentTotal) Error() string {
+	return fmt.Sprintf("inconsistent total count between pages - expected: %d, got: %d", e.expected, e.actual)
+}
+
+// CodeListClient is a handlers.CodeListClient that follows the paginated code list api endpoints to completion,
+// so that the results it returns contain every item rather than just the first page. Requests to endpoints that
+// are not paginated are passed straight through to the wrapped client.
+type CodeListClient struct {
+	handlers.CodeListClient
+	pages       PageClient
+	limit       int
+	maxInFlight int
+}
+
+// NewCodeListClient creates a CodeListClient that requests pages of up to limit items, with at most maxInFlight
+// page requests in flight at once
+func NewCodeListClient(client handlers.CodeListClient, pages PageClient, limit, maxInFlight int) *CodeListClient {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	return &CodeListClient{
+		CodeListClient: client,
+		pages:          pages,
+		limit:          limit,
+		maxInFlight:    maxInFlight,
+	}
+}
+
+// GetGeographyCodeLists returns every geography code list
+func (c *CodeListClient) GetGeographyCodeLists(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+	items, total, err := collect(ctx, c.limit, c.maxInFlight, func(ctx context.Context, offset, limit int) ([]codelist.CodeList, int, error) {
+		results, err := c.pages.GetGeographyCodeListsPage(ctx, userAuthToken, serviceAuthToken, offset, limit)
+		return results.Items, results.TotalCount, err
+	})
+	if err != nil {
+		return codelist.CodeListResults{}, err
+	}
+	return codelist.CodeListResults{Items: items, Count: total, Limit: total, TotalCount: total}, nil
+}
+
+// GetCodes returns every code of an edition of a code list
+func (c *CodeListClient) GetCodes(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+	items, total, err := collect(ctx, c.limit, c.maxInFlight, func(ctx context.Context, offset, limit int) ([]codelist.Item, int, error) {
+		results, err := c.pages.GetCodesPage(ctx, userAuthToken, serviceAuthToken, codeListID, edition, offset, limit)
+		return results.Items, results.TotalCount, err
+	})
+	if err != nil {
+		return codelist.CodesResults{}, err
+	}
+	return codelist.CodesResults{Items: items, Count: total, Limit: total, TotalCount: total}, nil
+}
+
+// GetDatasetsByCode returns every dataset related to a code
+func (c *CodeListClient) GetDatasetsByCode(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+	items, total, err := collect(ctx, c.limit, c.maxInFlight, func(ctx context.Context, offset, limit int) ([]codelist.Dataset, int, error) {
+		results, err := c.pages.GetDatasetsByCodePage(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID, offset, limit)
+		return results.Datasets, results.Count, err
+	})
+	if err != nil {
+		return codelist.DatasetsResult{}, err
+	}
+	return codelist.DatasetsResult{Datasets: items, Count: total}, nil
+}
+
+// collect requests the first page to find out the total number of items, then requests the remaining pages
+// with at most maxInFlight requests in flight. If the api returns smaller pages than requested, the size of
+// the first page is used for the remaining requests.
+func collect[T any](ctx context.Context, limit, maxInFlight int, fetch func(ctx context.Context, offset, limit int) ([]T, int, error)) ([]T, int, error) {
+	first, total, err := fetch(ctx, 0, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(first) >= total {
+		return first, total, nil
+	}
+	if len(first) == 0 {
+		return nil, 0, ErrInconsistentTotal{expected: total, actual: 0}
+	}
+
+	pageSize := len(first)
+	pages := make([][]T, (total+pageSize-1)/pageSize)
+	pages[0] = first
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	inFlight := make(chan struct{}, maxInFlight)
+
+	for i := 1; i < len(pages); i++ {
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			items, pageTotal, err := fetch(ctx, i*pageSize, pageSize)
+			if err == nil && pageTotal != total {
+				err = ErrInconsistentTotal{expected: total, actual: pageTotal}
+			}
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			pages[i] = items
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, 0, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, 0, ctx.Err()
+	}
+
+	items := make([]T, 0, total)
+	for _, page := range pages {
+		items = append(items, page...)
+	}
+	if len(items) != total {
+		return nil, 0, ErrInconsistentTotal{expected: total, actual: len(items)}
+	}
+	return items, total, nil
+}
@@ -0,0 +1,137 @@
+package paging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testCodes(total int) []codelist.Item {
+	var items []codelist.Item
+	for i := 0; i < total; i++ {
+		items = append(items, codelist.Item{Code: fmt.Sprintf("E%02d", i)})
+	}
+	return items
+}
+
+func codesPage(items []codelist.Item, offset, limit int) codelist.CodesResults {
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return codelist.CodesResults{
+		Items:      items[offset:end],
+		Count:      end - offset,
+		Offset:     offset,
+		Limit:      limit,
+		TotalCount: len(items),
+	}
+}
+
+func TestCodeListClient(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a paging code list client with a page limit of 3", t, func() {
+		codes := testCodes(10)
+		var mutex sync.Mutex
+		inFlight, maxInFlight := 0, 0
+
+		mockPages := &PageClientMock{
+			GetCodesPageFunc: func(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition string, offset, limit int) (codelist.CodesResults, error) {
+				mutex.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mutex.Unlock()
+				defer func() {
+					mutex.Lock()
+					inFlight--
+					mutex.Unlock()
+				}()
+				return codesPage(codes, offset, limit), nil
+			},
+		}
+		cli := NewCodeListClient(&handlers.CodeListClientMock{}, mockPages, 3, 2)
+
+		Convey("every page is requested and the items are returned in order", func() {
+			results, err := cli.GetCodes(ctx, "", "", "local-authority", "2018")
+			So(err, ShouldBeNil)
+			So(results.Items, ShouldResemble, codes)
+			So(results.Count, ShouldEqual, 10)
+			So(results.TotalCount, ShouldEqual, 10)
+			So(mockPages.GetCodesPageCalls(), ShouldHaveLength, 4)
+			So(maxInFlight, ShouldBeLessThanOrEqualTo, 2)
+		})
+
+		Convey("smaller pages than requested are followed using the size of the first page", func() {
+			mockPages.GetCodesPageFunc = func(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition string, offset, limit int) (codelist.CodesResults, error) {
+				return codesPage(codes, offset, 2), nil
+			}
+			results, err := cli.GetCodes(ctx, "", "", "local-authority", "2018")
+			So(err, ShouldBeNil)
+			So(results.Items, ShouldResemble, codes)
+			So(mockPages.GetCodesPageCalls(), ShouldHaveLength, 5)
+		})
+
+		Convey("an error is returned if the pages disagree about the total count", func() {
+			mockPages.GetCodesPageFunc = func(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition string, offset, limit int) (codelist.CodesResults, error) {
+				page := codesPage(codes, offset, limit)
+				if offset > 0 {
+					page.TotalCount = 11
+				}
+				return page, nil
+			}
+			_, err := cli.GetCodes(ctx, "", "", "local-authority", "2018")
+			So(err, ShouldResemble, ErrInconsistentTotal{expected: 10, actual: 11})
+		})
+
+		Convey("an error requesting a page is returned", func() {
+			errPage := errors.New("code list api unavailable")
+			mockPages.GetCodesPageFunc = func(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition string, offset, limit int) (codelist.CodesResults, error) {
+				if offset == 6 {
+					return codelist.CodesResults{}, errPage
+				}
+				return codesPage(codes, offset, limit), nil
+			}
+			_, err := cli.GetCodes(ctx, "", "", "local-authority", "2018")
+			So(err, ShouldEqual, errPage)
+		})
+	})
+
+	Convey("Given a paging code list client and a single page of datasets", t, func() {
+		mockPages := &PageClientMock{
+			GetDatasetsByCodePageFunc: func(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition, codeID string, offset, limit int) (codelist.DatasetsResult, error) {
+				return codelist.DatasetsResult{Datasets: []codelist.Dataset{{DimensionLabal: "Adur"}}, Count: 1}, nil
+			},
+		}
+		mockClient := &handlers.CodeListClientMock{
+			GetCodeByIDFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+				return codelist.CodeResult{ID: codeID}, nil
+			},
+		}
+		cli := NewCodeListClient(mockClient, mockPages, 3, 2)
+
+		Convey("only the first page is requested", func() {
+			results, err := cli.GetDatasetsByCode(ctx, "", "", "local-authority", "2018", "E07000223")
+			So(err, ShouldBeNil)
+			So(results.Datasets, ShouldHaveLength, 1)
+			So(mockPages.GetDatasetsByCodePageCalls(), ShouldHaveLength, 1)
+			So(mockPages.GetDatasetsByCodePageCalls()[0].Offset, ShouldEqual, 0)
+			So(mockPages.GetDatasetsByCodePageCalls()[0].Limit, ShouldEqual, 3)
+		})
+
+		Convey("requests to endpoints that are not paginated are passed to the wrapped client", func() {
+			code, err := cli.GetCodeByID(ctx, "", "", "local-authority", "2018", "E07000223")
+			So(err, ShouldBeNil)
+			So(code.ID, ShouldEqual, "E07000223")
+			So(mockClient.GetCodeByIDCalls(), ShouldHaveLength, 1)
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+// CodeListClient is a handlers.CodeListClient that retries failed calls to the client it wraps
+type CodeListClient struct {
+	client  handlers.CodeListClient
+	retrier *retrier
+}
+
+// NewCodeListClient wraps the provided client so that failed calls are retried according to policy
+func NewCodeListClient(client handlers.CodeListClient, policy Policy) *CodeListClient {
+	return &CodeListClient{
+		client:  client,
+		retrier: &retrier{name: "codelist", policy: policy},
+	}
+}
+
+// GetGeographyCodeLists returns the geography code lists, retrying transient failures
+func (c *CodeListClient) GetGeographyCodeLists(ctx context.Context, userAuthToken string, serviceAuthToken string) (results codelist.CodeListResults, err error) {
+	err = c.retrier.do(ctx, "GetGeographyCodeLists", func() (err error) {
+		results, err = c.client.GetGeographyCodeLists(ctx, userAuthToken, serviceAuthToken)
+		return err
+	})
+	return results, err
+}
+
+// GetCodeListEditions returns the editions of a code list, retrying transient failures
+func (c *CodeListClient) GetCodeListEditions(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (editions codelist.EditionsListResults, err error) {
+	err = c.retrier.do(ctx, "GetCodeListEditions", func() (err error) {
+		editions, err = c.client.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
+		return err
+	})
+	return editions, err
+}
+
+// GetCodes returns the codes of an edition of a code list, retrying transient failures
+func (c *CodeListClient) GetCodes(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codes codelist.CodesResults, err error) {
+	err = c.retrier.do(ctx, "GetCodes", func() (err error) {
+		codes, err = c.client.GetCodes(ctx, userAuthToken, serviceAuthToken, codeListID, edition)
+		return err
+	})
+	return codes, err
+}
+
+// GetCodeByID returns a single code of an edition of a code list, retrying transient failures
+func (c *CodeListClient) GetCodeByID(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (code codelist.CodeResult, err error) {
+	err = c.retrier.do(ctx, "GetCodeByID", func() (err error) {
+		code, err = c.client.GetCodeByID(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+		return err
+	})
+	return code, err
+}
+
+// GetDatasetsByCode returns the datasets related to a code, retrying transient failures
+func (c *CodeListClient) GetDatasetsByCode(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (datasets codelist.DatasetsResult, err error) {
+	err = c.retrier.do(ctx, "GetDatasetsByCode", func() (err error) {
+		datasets, err = c.client.GetDatasetsByCode(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+		return err
+	})
+	return datasets, err
+}
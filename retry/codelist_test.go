@@ -0,0 +1,75 @@
+package retry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-api-clients-go/health"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCodeListClient(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a retrying code list client and a code list API that is briefly unavailable", t, func() {
+		mockClient := &handlers.CodeListClientMock{}
+		mockClient.GetCodesFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+			if len(mockClient.GetCodesCalls()) == 1 {
+				return codelist.CodesResults{}, testStatusError(http.StatusServiceUnavailable)
+			}
+			return codelist.CodesResults{Count: 1}, nil
+		}
+		cli := NewCodeListClient(mockClient, Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+		Convey("the call is retried with the same arguments and its result returned", func() {
+			codes, err := cli.GetCodes(ctx, "", "service-token", "local-authority", "2018")
+			So(err, ShouldBeNil)
+			So(codes.Count, ShouldEqual, 1)
+
+			calls := mockClient.GetCodesCalls()
+			So(calls, ShouldHaveLength, 2)
+			So(calls[1].CodeListID, ShouldEqual, "local-authority")
+			So(calls[1].Edition, ShouldEqual, "2018")
+			So(calls[1].ServiceAuthToken, ShouldEqual, "service-token")
+		})
+	})
+}
+
+func TestCodeListClientAttempts(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a retrying code list client on an HTTP client with its own retries disabled", t, func() {
+		status := http.StatusOK
+		requests := 0
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			w.WriteHeader(status)
+		}))
+		defer api.Close()
+
+		httpClient := dphttp.NewClient()
+		httpClient.SetMaxRetries(0)
+		codeListClient := codelist.NewWithHealthClient(health.NewClientWithClienter("api-router", api.URL, httpClient))
+		cli := NewCodeListClient(codeListClient, Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+		Convey("a 503 is requested once for each attempt of the policy", func() {
+			status = http.StatusServiceUnavailable
+			_, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			So(err, ShouldNotBeNil)
+			So(requests, ShouldEqual, 3)
+		})
+
+		Convey("a 500 is requested only once", func() {
+			status = http.StatusInternalServerError
+			_, err := cli.GetCodeListEditions(ctx, "", "", "local-authority")
+			So(err, ShouldNotBeNil)
+			So(requests, ShouldEqual, 1)
+		})
+	})
+}
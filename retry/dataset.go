@@ -0,0 +1,40 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-api-clients-go/dataset"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+)
+
+// DatasetClient is a handlers.DatasetClient that retries failed calls to the client it wraps
+type DatasetClient struct {
+	client  handlers.DatasetClient
+	retrier *retrier
+}
+
+// NewDatasetClient wraps the provided client so that failed calls are retried according to policy
+func NewDatasetClient(client handlers.DatasetClient, policy Policy) *DatasetClient {
+	return &DatasetClient{
+		client:  client,
+		retrier: &retrier{name: "dataset", policy: policy},
+	}
+}
+
+// Get returns the details of a dataset, retrying transient failures
+func (c *DatasetClient) Get(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID string) (details dataset.DatasetDetails, err error) {
+	err = c.retrier.do(ctx, "Get", func() (err error) {
+		details, err = c.client.Get(ctx, userAuthToken, serviceAuthToken, collectionID, datasetID)
+		return err
+	})
+	return details, err
+}
+
+// GetVersion returns a version of an edition of a dataset, retrying transient failures
+func (c *DatasetClient) GetVersion(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (m dataset.Version, err error) {
+	err = c.retrier.do(ctx, "GetVersion", func() (err error) {
+		m, err = c.client.GetVersion(ctx, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version)
+		return err
+	})
+	return m, err
+}
@@ -0,0 +1,50 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/paging"
+)
+
+// PageClient is a paging.PageClient that retries failed requests for a single page, so that a failure part way
+// through a paginated response does not request the pages before it again
+type PageClient struct {
+	client  paging.PageClient
+	retrier *retrier
+}
+
+// NewPageClient wraps the provided client so that failed page requests are retried according to policy
+func NewPageClient(client paging.PageClient, policy Policy) *PageClient {
+	return &PageClient{
+		client:  client,
+		retrier: &retrier{name: "codelist", policy: policy},
+	}
+}
+
+// GetGeographyCodeListsPage returns a page of the geography code lists, retrying transient failures
+func (c *PageClient) GetGeographyCodeListsPage(ctx context.Context, userAuthToken, serviceAuthToken string, offset, limit int) (results codelist.CodeListResults, err error) {
+	err = c.retrier.do(ctx, "GetGeographyCodeListsPage", func() (err error) {
+		results, err = c.client.GetGeographyCodeListsPage(ctx, userAuthToken, serviceAuthToken, offset, limit)
+		return err
+	})
+	return results, err
+}
+
+// GetCodesPage returns a page of the codes of an edition of a code list, retrying transient failures
+func (c *PageClient) GetCodesPage(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition string, offset, limit int) (codes codelist.CodesResults, err error) {
+	err = c.retrier.do(ctx, "GetCodesPage", func() (err error) {
+		codes, err = c.client.GetCodesPage(ctx, userAuthToken, serviceAuthToken, codeListID, edition, offset, limit)
+		return err
+	})
+	return codes, err
+}
+
+// GetDatasetsByCodePage returns a page of the datasets related to a code, retrying transient failures
+func (c *PageClient) GetDatasetsByCodePage(ctx context.Context, userAuthToken, serviceAuthToken, codeListID, edition, codeID string, offset, limit int) (datasets codelist.DatasetsResult, err error) {
+	err = c.retrier.do(ctx, "GetDatasetsByCodePage", func() (err error) {
+		datasets, err = c.client.GetDatasetsByCodePage(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID, offset, limit)
+		return err
+	})
+	return datasets, err
+}
@@ -0,0 +1,46 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-geography-controller/paging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPageClient(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a paging code list client over a retrying page client, and a page that is briefly unavailable", t, func() {
+		failed := false
+		mockPages := &paging.PageClientMock{
+			GetCodesPageFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, offset int, limit int) (codelist.CodesResults, error) {
+				if offset == 4 && !failed {
+					failed = true
+					return codelist.CodesResults{}, testStatusError(http.StatusServiceUnavailable)
+				}
+				items := []codelist.Item{{Code: fmt.Sprint(offset)}, {Code: fmt.Sprint(offset + 1)}}
+				return codelist.CodesResults{Items: items, Count: len(items), TotalCount: 6}, nil
+			},
+		}
+		pages := NewPageClient(mockPages, Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+		cli := paging.NewCodeListClient(&handlers.CodeListClientMock{}, pages, 2, 1)
+
+		Convey("only the page that failed is requested again", func() {
+			codes, err := cli.GetCodes(ctx, "", "service-token", "local-authority", "2018")
+			So(err, ShouldBeNil)
+			So(codes.Items, ShouldHaveLength, 6)
+
+			var offsets []int
+			for _, call := range mockPages.GetCodesPageCalls() {
+				offsets = append(offsets, call.Offset)
+			}
+			So(offsets, ShouldResemble, []int{0, 2, 4, 4})
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Policy configures how failed downstream calls are retried
+type Policy struct {
+	// MaxAttempts is the maximum number of times a call is made, including the first. One or less disables retries.
+	MaxAttempts int
+	// InitialBackoff is the upper bound of the wait before the first retry, doubling for each retry after it
+	InitialBackoff time.Duration
+	// MaxBackoff caps the upper bound of the wait before any retry
+	MaxBackoff time.Duration
+}
+
+// backoff returns the upper bound of the wait before retrying a call that has failed attempt times
+func (p Policy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// random is a seeded source of jitter shared by every retrier, so that instances of the service do not retry in step
+var random = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// jitter returns the wait before a retry with a backoff of d
+var jitter = defaultJitter
+
+// defaultJitter returns a random duration between zero and d, so that callers that failed together spread their
+// retries out
+func defaultJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	random.Lock()
+	defer random.Unlock()
+	return time.Duration(random.Int63n(int64(d) + 1))
+}
+
+// retrier retries the calls of a single client according to a policy
+type retrier struct {
+	name   string
+	policy Policy
+}
+
+// do calls fn, retrying it with exponential backoff and jitter while it fails with a retryable error and attempts
+// remain. A retry is not made if its wait would not finish before ctx's deadline, and the wait is abandoned if ctx
+// is done. The error of the last attempt made is returned.
+func (r *retrier) do(ctx context.Context, method string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.policy.MaxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		logData := log.Data{"client": r.name, "method": method, "attempt": attempt, "max_attempts": r.policy.MaxAttempts}
+		wait := jitter(r.policy.backoff(attempt))
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			log.Warn(ctx, "not retrying downstream call, not enough time left before the deadline", logData, log.FormatErrors([]error{err}))
+			return err
+		}
+
+		logData["wait"] = wait.String()
+		log.Warn(ctx, "retrying downstream call", logData, log.FormatErrors([]error{err}))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// isRetryable returns true if err is a transport error, or a 502, 503 or 504 response. Other responses would be the
+// same if the call was made again, and a call that was cancelled or ran out of time has no time left to retry in.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		switch coded.Code() {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
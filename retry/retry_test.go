@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testStatusError int
+
+func (e testStatusError) Error() string { return http.StatusText(int(e)) }
+func (e testStatusError) Code() int     { return int(e) }
+
+func TestPolicy(t *testing.T) {
+
+	Convey("Given a policy with an initial backoff of 100ms capped at 1s", t, func() {
+		p := Policy{MaxAttempts: 10, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+		Convey("the backoff doubles with each attempt until it reaches the cap", func() {
+			So(p.backoff(1), ShouldEqual, 100*time.Millisecond)
+			So(p.backoff(2), ShouldEqual, 200*time.Millisecond)
+			So(p.backoff(4), ShouldEqual, 800*time.Millisecond)
+			So(p.backoff(5), ShouldEqual, time.Second)
+			So(p.backoff(100), ShouldEqual, time.Second)
+		})
+
+		Convey("the jittered wait is never more than the backoff", func() {
+			for i := 0; i < 100; i++ {
+				wait := jitter(p.backoff(1))
+				So(wait, ShouldBeGreaterThanOrEqualTo, 0)
+				So(wait, ShouldBeLessThanOrEqualTo, 100*time.Millisecond)
+			}
+		})
+	})
+}
+
+func TestRetrier(t *testing.T) {
+	ctx := context.Background()
+	errReset := errors.New("connection reset by peer")
+
+	Convey("Given a retrier that makes up to 3 attempts", t, func() {
+		r := &retrier{name: "codelist", policy: Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}}
+
+		var errs []error
+		calls := 0
+		fn := func() error {
+			calls++
+			if calls > len(errs) {
+				return nil
+			}
+			return errs[calls-1]
+		}
+
+		Convey("transport errors and 502, 503 and 504 responses are retried until a call succeeds", func() {
+			errs = []error{errReset, testStatusError(http.StatusBadGateway)}
+			So(r.do(ctx, "GetCodes", fn), ShouldBeNil)
+			So(calls, ShouldEqual, 3)
+
+			calls = 0
+			errs = []error{testStatusError(http.StatusServiceUnavailable), testStatusError(http.StatusGatewayTimeout)}
+			So(r.do(ctx, "GetCodes", fn), ShouldBeNil)
+			So(calls, ShouldEqual, 3)
+		})
+
+		Convey("the last error is returned once every attempt has failed", func() {
+			errs = []error{errReset, errReset, testStatusError(http.StatusServiceUnavailable), errReset}
+			So(r.do(ctx, "GetCodes", fn), ShouldEqual, testStatusError(http.StatusServiceUnavailable))
+			So(calls, ShouldEqual, 3)
+		})
+
+		Convey("other responses, cancellations and timeouts are not retried", func() {
+			for _, err := range []error{testStatusError(http.StatusNotFound), testStatusError(http.StatusInternalServerError), context.Canceled, context.DeadlineExceeded} {
+				calls = 0
+				errs = []error{err}
+				So(errors.Is(r.do(ctx, "GetCodes", fn), err), ShouldBeTrue)
+				So(calls, ShouldEqual, 1)
+			}
+		})
+
+		Convey("a retry is not made if its wait would not finish before the deadline", func() {
+			r.policy.InitialBackoff = time.Hour
+			r.policy.MaxBackoff = time.Hour
+			jitter = func(d time.Duration) time.Duration { return d }
+			defer func() { jitter = defaultJitter }()
+
+			deadlineCtx, cancel := context.WithTimeout(ctx, time.Minute)
+			defer cancel()
+			errs = []error{errReset}
+			So(r.do(deadlineCtx, "GetCodes", fn), ShouldEqual, errReset)
+			So(calls, ShouldEqual, 1)
+		})
+
+		Convey("the wait before a retry is abandoned when the context is done", func() {
+			r.policy.InitialBackoff = time.Hour
+			r.policy.MaxBackoff = time.Hour
+			jitter = func(d time.Duration) time.Duration { return d }
+			defer func() { jitter = defaultJitter }()
+
+			cancelCtx, cancel := context.WithCancel(ctx)
+			errs = []error{errReset}
+			time.AfterFunc(10*time.Millisecond, cancel)
+			So(r.do(cancelCtx, "GetCodes", fn), ShouldEqual, errReset)
+			So(calls, ShouldEqual, 1)
+		})
+	})
+}
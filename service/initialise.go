@@ -1,18 +1,21 @@
 package service
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/ONSdigital/dp-api-clients-go/health"
 	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/events"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 	dphttp "github.com/ONSdigital/dp-net/http"
 )
 
 // ExternalServiceList holds the initialiser and initialisation state of external services.
 type ExternalServiceList struct {
-	HealthCheck bool
-	Init        Initialiser
+	HealthCheck   bool
+	EventConsumer bool
+	Init          Initialiser
 }
 
 // NewServiceList creates a new service list with the provided initialiser
@@ -46,6 +49,16 @@ func (e *ExternalServiceList) GetHealthCheck(cfg *config.Config, buildTime, gitC
 	return hc, nil
 }
 
+// GetEventConsumer creates a change event consumer and sets the EventConsumer flag to true
+func (e *ExternalServiceList) GetEventConsumer(cfg *config.Config) (events.Consumer, error) {
+	consumer, err := e.Init.DoGetEventConsumer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	e.EventConsumer = true
+	return consumer, nil
+}
+
 // DoGetHTTPServer creates an HTTP Server with the provided bind address and router
 func (e *Init) DoGetHTTPServer(bindAddr string, router http.Handler) HTTPServer {
 	s := dphttp.NewServer(bindAddr, router)
@@ -67,3 +80,11 @@ func (e *Init) DoGetHealthCheck(cfg *config.Config, buildTime, gitCommit, versio
 	hc := healthcheck.New(versionInfo, cfg.HealthCheckCriticalTimeout, cfg.HealthCheckInterval)
 	return &hc, nil
 }
+
+// DoGetEventConsumer creates a consumer of change events from the configured Kafka topic
+func (e *Init) DoGetEventConsumer(cfg *config.Config) (events.Consumer, error) {
+	if len(cfg.KafkaAddr) == 0 || cfg.KafkaChangeEventsTopic == "" {
+		return nil, errors.New("kafka brokers and change events topic must be configured to consume change events")
+	}
+	return events.NewKafka(cfg.KafkaAddr, cfg.KafkaChangeEventsTopic, cfg.KafkaConsumerGroup), nil
+}
@@ -6,15 +6,17 @@ package mock
 import (
 	"github.com/ONSdigital/dp-api-clients-go/health"
 	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/events"
 	"github.com/ONSdigital/dp-frontend-geography-controller/service"
 	"net/http"
 	"sync"
 )
 
 var (
-	lockInitialiserMockDoGetHTTPServer   sync.RWMutex
-	lockInitialiserMockDoGetHealthCheck  sync.RWMutex
-	lockInitialiserMockDoGetHealthClient sync.RWMutex
+	lockInitialiserMockDoGetEventConsumer sync.RWMutex
+	lockInitialiserMockDoGetHTTPServer    sync.RWMutex
+	lockInitialiserMockDoGetHealthCheck   sync.RWMutex
+	lockInitialiserMockDoGetHealthClient  sync.RWMutex
 )
 
 // Ensure, that InitialiserMock does implement service.Initialiser.
@@ -27,6 +29,9 @@ var _ service.Initialiser = &InitialiserMock{}
 //
 //         // make and configure a mocked service.Initialiser
 //         mockedInitialiser := &InitialiserMock{
+//             DoGetEventConsumerFunc: func(cfg *config.Config) (events.Consumer, error) {
+// 	               panic("mock out the DoGetEventConsumer method")
+//             },
 //             DoGetHTTPServerFunc: func(bindAddr string, router http.Handler) service.HTTPServer {
 // 	               panic("mock out the DoGetHTTPServer method")
 //             },
@@ -43,6 +48,9 @@ var _ service.Initialiser = &InitialiserMock{}
 //
 //     }
 type InitialiserMock struct {
+	// DoGetEventConsumerFunc mocks the DoGetEventConsumer method.
+	DoGetEventConsumerFunc func(cfg *config.Config) (events.Consumer, error)
+
 	// DoGetHTTPServerFunc mocks the DoGetHTTPServer method.
 	DoGetHTTPServerFunc func(bindAddr string, router http.Handler) service.HTTPServer
 
@@ -54,6 +62,11 @@ type InitialiserMock struct {
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// DoGetEventConsumer holds details about calls to the DoGetEventConsumer method.
+		DoGetEventConsumer []struct {
+			// Cfg is the cfg argument value.
+			Cfg *config.Config
+		}
 		// DoGetHTTPServer holds details about calls to the DoGetHTTPServer method.
 		DoGetHTTPServer []struct {
 			// BindAddr is the bindAddr argument value.
@@ -82,6 +95,37 @@ type InitialiserMock struct {
 	}
 }
 
+// DoGetEventConsumer calls DoGetEventConsumerFunc.
+func (mock *InitialiserMock) DoGetEventConsumer(cfg *config.Config) (events.Consumer, error) {
+	if mock.DoGetEventConsumerFunc == nil {
+		panic("InitialiserMock.DoGetEventConsumerFunc: method is nil but Initialiser.DoGetEventConsumer was just called")
+	}
+	callInfo := struct {
+		Cfg *config.Config
+	}{
+		Cfg: cfg,
+	}
+	lockInitialiserMockDoGetEventConsumer.Lock()
+	mock.calls.DoGetEventConsumer = append(mock.calls.DoGetEventConsumer, callInfo)
+	lockInitialiserMockDoGetEventConsumer.Unlock()
+	return mock.DoGetEventConsumerFunc(cfg)
+}
+
+// DoGetEventConsumerCalls gets all the calls that were made to DoGetEventConsumer.
+// Check the length with:
+//     len(mockedInitialiser.DoGetEventConsumerCalls())
+func (mock *InitialiserMock) DoGetEventConsumerCalls() []struct {
+	Cfg *config.Config
+} {
+	var calls []struct {
+		Cfg *config.Config
+	}
+	lockInitialiserMockDoGetEventConsumer.RLock()
+	calls = mock.calls.DoGetEventConsumer
+	lockInitialiserMockDoGetEventConsumer.RUnlock()
+	return calls
+}
+
 // DoGetHTTPServer calls DoGetHTTPServerFunc.
 func (mock *InitialiserMock) DoGetHTTPServer(bindAddr string, router http.Handler) service.HTTPServer {
 	if mock.DoGetHTTPServerFunc == nil {
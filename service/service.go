@@ -2,17 +2,30 @@ package service
 
 import (
 	"context"
+	"expvar"
 	"net/url"
 
 	"github.com/ONSdigital/dp-api-clients-go/codelist"
 	"github.com/ONSdigital/dp-api-clients-go/dataset"
 	"github.com/ONSdigital/dp-api-clients-go/health"
 	"github.com/ONSdigital/dp-api-clients-go/renderer"
+	"github.com/ONSdigital/dp-frontend-geography-controller/admin"
+	"github.com/ONSdigital/dp-frontend-geography-controller/breaker"
+	"github.com/ONSdigital/dp-frontend-geography-controller/cache"
+	"github.com/ONSdigital/dp-frontend-geography-controller/coalesce"
 	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/events"
 	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"github.com/ONSdigital/dp-frontend-geography-controller/metrics"
+	"github.com/ONSdigital/dp-frontend-geography-controller/pagecache"
+	"github.com/ONSdigital/dp-frontend-geography-controller/paging"
+	"github.com/ONSdigital/dp-frontend-geography-controller/retry"
+	"github.com/ONSdigital/dp-frontend-geography-controller/tracing"
+	"github.com/ONSdigital/dp-frontend-geography-controller/warmer"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // Service contains all the configs, server and clients to run the frontend homepage controller
@@ -21,9 +34,19 @@ type Service struct {
 	routerHealthClient *health.Client
 	HealthCheck        HealthChecker
 	Server             HTTPServer
+	AdminServer        HTTPServer
 	CodelistClient     *codelist.Client
+	CodelistCache      *cache.CodeListClient
 	DatasetClient      *dataset.Client
 	RendererClient     *renderer.Renderer
+	CodeListBreaker    *breaker.Breaker
+	DatasetBreaker     *breaker.Breaker
+	RendererBreaker    *breaker.Breaker
+	PageCache          *pagecache.Cache
+	Warmer             *warmer.Warmer
+	EventConsumer      events.Consumer
+	Metrics            *metrics.Metrics
+	TracerProvider     *sdktrace.TracerProvider
 	ServiceList        *ExternalServiceList
 }
 
@@ -43,13 +66,45 @@ func Run(ctx context.Context, cfg *config.Config, serviceList *ExternalServiceLi
 		return nil, err
 	}
 
-	// Get health client for api router
+	// Set up tracing before any requests can be traced
+	svc.TracerProvider, err = tracing.Init(ctx, cfg)
+	if err != nil {
+		log.Error(ctx, "failed to initialise tracing", err)
+		return nil, err
+	}
+
+	// Get health client for api router, and a copy of it that traces requests, so that health checks are not traced.
+	// The retries of its HTTP client are disabled, so that the retry policy is the only layer retrying API calls.
 	svc.routerHealthClient = serviceList.GetHealthClient("api-router", cfg.APIRouterURL)
+	svc.routerHealthClient.Client.SetMaxRetries(0)
+	tracedRouterClient := health.NewClientWithClienter("api-router", cfg.APIRouterURL, tracing.NewClienter(svc.routerHealthClient.Client))
 
 	// Initialise clients
-	svc.CodelistClient = codelist.NewWithHealthClient(svc.routerHealthClient)
-	svc.DatasetClient = dataset.NewWithHealthClient(svc.routerHealthClient)
+	svc.CodelistClient = codelist.NewWithHealthClient(tracedRouterClient)
+	svc.DatasetClient = dataset.NewWithHealthClient(tracedRouterClient)
 	svc.RendererClient = renderer.New(cfg.RendererURL)
+	svc.Metrics = metrics.New()
+	svc.CodeListBreaker = breaker.New("code list API", cfg.CodeListBreakerThreshold, cfg.BreakerOpenTimeout)
+	svc.DatasetBreaker = breaker.New("dataset API", cfg.DatasetBreakerThreshold, cfg.BreakerOpenTimeout)
+	svc.RendererBreaker = breaker.New("frontend renderer", cfg.RendererBreakerThreshold, cfg.BreakerOpenTimeout)
+	retryPolicy := retry.Policy{MaxAttempts: cfg.RetryMaxAttempts, InitialBackoff: cfg.RetryInitialBackoff, MaxBackoff: cfg.RetryMaxBackoff}
+	codelistPages := retry.NewPageClient(metrics.NewPageClient(paging.NewClient(tracedRouterClient), svc.Metrics), retryPolicy)
+	codelistClient := breaker.NewCodeListClient(paging.NewCodeListClient(retry.NewCodeListClient(metrics.NewCodeListClient(svc.CodelistClient, svc.Metrics), retryPolicy), codelistPages, cfg.CodeListAPIPageLimit, cfg.CodeListAPIPagesInFlight), svc.CodeListBreaker)
+	svc.CodelistCache = cache.NewCodeListClient(coalesce.NewCodeListClient(codelistClient, cfg.CoalesceCallTimeout, svc.Metrics.ObserveCoalesced), cfg)
+	codeListCache := tracing.NewCodeListClient(svc.CodelistCache)
+	datasetClient := tracing.NewDatasetClient(coalesce.NewDatasetClient(breaker.NewDatasetClient(retry.NewDatasetClient(metrics.NewDatasetClient(svc.DatasetClient, svc.Metrics), retryPolicy), svc.DatasetBreaker), cfg.CoalesceCallTimeout, svc.Metrics.ObserveCoalesced))
+	rendererClient := breaker.NewRenderClient(metrics.NewRenderClient(svc.RendererClient, svc.Metrics), svc.RendererBreaker)
+	svc.PageCache = pagecache.New(cfg.PageCacheTTL, cfg.PageCacheMaxBytes)
+	svc.Warmer = warmer.New(svc.CodelistCache, cfg.CacheWarmerInterval, cfg.CacheWarmerConcurrency, cfg.CacheWarmerEnabled && cfg.CacheWarmerHoldReadiness)
+
+	// Get the consumer of change events, which invalidate the caches
+	if cfg.KafkaEnabled {
+		svc.EventConsumer, err = serviceList.GetEventConsumer(cfg)
+		if err != nil {
+			log.Error(ctx, "failed to create change event consumer", err)
+			return nil, err
+		}
+	}
 
 	// Get healthcheck with checkers
 	svc.HealthCheck, err = serviceList.GetHealthCheck(cfg, buildTime, gitCommit, version)
@@ -63,21 +118,49 @@ func Run(ctx context.Context, cfg *config.Config, serviceList *ExternalServiceLi
 
 	// Initialise router
 	router := mux.NewRouter()
+	router.Use(tracing.Middleware)
+	router.Use(svc.Metrics.Middleware)
+	svc.Metrics.MeasureUnmatched(router)
+	router.NotFoundHandler = tracing.Middleware(router.NotFoundHandler)
+	router.MethodNotAllowedHandler = tracing.Middleware(router.MethodNotAllowedHandler)
 	router.StrictSlash(true).Path("/health").HandlerFunc(svc.HealthCheck.Handler)
+	router.StrictSlash(true).Path("/debug/vars").Methods("GET").Handler(expvar.Handler())
+	router.StrictSlash(true).Path("/metrics").Methods("GET").Handler(svc.Metrics.Handler())
 
-	router.StrictSlash(true).Path("/geography").Methods("GET").HandlerFunc(handlers.HomepageRender(svc.RendererClient, svc.CodelistClient))
-	router.StrictSlash(true).Path("/geography/{codeListID}").Methods("GET").HandlerFunc(handlers.ListPageRender(svc.RendererClient, svc.CodelistClient))
-	router.StrictSlash(true).Path("/geography/{codeListID}/{codeID}").Methods("GET").HandlerFunc(handlers.AreaPageRender(svc.RendererClient, svc.CodelistClient, svc.DatasetClient, apiRouterVersion))
+	router.StrictSlash(true).Path("/geography").Methods("GET").HandlerFunc(handlers.CacheControl(cfg.HomepageCacheControl, svc.PageCache.Middleware(handlers.HomepageRender(*cfg, rendererClient, codeListCache))))
+	router.StrictSlash(true).Path("/geography/{codeListID}.csv").Methods("GET").HandlerFunc(handlers.CacheControl(cfg.CSVCacheControl, handlers.ListCSVDownload(rendererClient, codeListCache)))
+	router.StrictSlash(true).Path("/geography/{codeListID}").Methods("GET").HandlerFunc(handlers.CacheControl(cfg.ListPageCacheControl, svc.PageCache.Middleware(handlers.ListPageRender(*cfg, rendererClient, codeListCache))))
+	router.StrictSlash(true).Path("/geography/{codeListID}/editions/{edition}").Methods("GET").HandlerFunc(handlers.CacheControl(cfg.ListPageCacheControl, svc.PageCache.Middleware(handlers.ListPageRender(*cfg, rendererClient, codeListCache))))
+	router.StrictSlash(true).Path("/geography/{codeListID}/{codeID}").Methods("GET").HandlerFunc(handlers.CacheControl(cfg.AreaPageCacheControl, svc.PageCache.Middleware(handlers.AreaPageRender(*cfg, rendererClient, codeListCache, datasetClient, apiRouterVersion))))
 
 	svc.Server = serviceList.GetHTTPServer(cfg.BindAddr, router)
+	if cfg.AdminBindAddr != "" {
+		if cfg.AdminSecret == "" {
+			log.Warn(ctx, "admin API has no secret configured, so every request to it will be rejected", log.Data{"admin_bind_addr": cfg.AdminBindAddr})
+		}
+		svc.AdminServer = serviceList.GetHTTPServer(cfg.AdminBindAddr, admin.New(cfg, svc.CodelistCache, svc.PageCache).Router())
+	}
 
-	// Start Healthcheck and HTTP Server
+	// Start the cache warmer, change event consumer, Healthcheck and HTTP Servers
+	if cfg.CacheWarmerEnabled {
+		svc.Warmer.Start(ctx)
+	}
+	if serviceList.EventConsumer {
+		svc.EventConsumer.Start(ctx, events.NewInvalidator(svc.CodelistCache, svc.PageCache).Handle)
+	}
 	svc.HealthCheck.Start(ctx)
 	go func() {
 		if err := svc.Server.ListenAndServe(); err != nil {
 			svcErrors <- errors.Wrap(err, "failure in http listen and serve")
 		}
 	}()
+	if svc.AdminServer != nil {
+		go func() {
+			if err := svc.AdminServer.ListenAndServe(); err != nil {
+				svcErrors <- errors.Wrap(err, "failure in admin http listen and serve")
+			}
+		}()
+	}
 
 	return svc, nil
 }
@@ -102,6 +185,35 @@ func (svc *Service) Close(ctx context.Context) error {
 			log.Error(ctx, "failed to shutdown http server", err)
 			hasShutdownError = true
 		}
+
+		// stop admin requests, which inspect and purge the caches
+		if svc.AdminServer != nil {
+			if err := svc.AdminServer.Shutdown(ctx); err != nil {
+				log.Error(ctx, "failed to shutdown admin http server", err)
+				hasShutdownError = true
+			}
+		}
+
+		// stop consuming change events once nothing can be served from the caches
+		if svc.ServiceList.EventConsumer {
+			if err := svc.EventConsumer.Stop(ctx); err != nil {
+				log.Error(ctx, "failed to stop change event consumer", err)
+				hasShutdownError = true
+			}
+		}
+
+		// stop warming the cache once nothing can be served from it
+		if svc.Warmer != nil {
+			svc.Warmer.Stop()
+		}
+
+		// export the remaining spans once nothing else can be traced
+		if svc.TracerProvider != nil {
+			if err := svc.TracerProvider.Shutdown(ctx); err != nil {
+				log.Error(ctx, "failed to shutdown tracer provider", err)
+				hasShutdownError = true
+			}
+		}
 	}()
 
 	// wait for shutdown success (via cancel) or failure (timeout)
@@ -138,6 +250,32 @@ func (svc *Service) registerCheckers(ctx context.Context, cfg *config.Config) (e
 		log.Error(ctx, "failed to add frontend renderer checker", err)
 	}
 
+	if err = svc.HealthCheck.AddCheck("geography cache", svc.CodelistCache.Checker); err != nil {
+		hasErrors = true
+		log.Error(ctx, "failed to add geography cache checker", err)
+	}
+
+	if cfg.CacheWarmerEnabled {
+		if err = svc.HealthCheck.AddCheck("cache warmer", svc.Warmer.Checker); err != nil {
+			hasErrors = true
+			log.Error(ctx, "failed to add cache warmer checker", err)
+		}
+	}
+
+	if svc.ServiceList.EventConsumer {
+		if err = svc.HealthCheck.AddCheck("change event consumer", svc.EventConsumer.Checker); err != nil {
+			hasErrors = true
+			log.Error(ctx, "failed to add change event consumer checker", err)
+		}
+	}
+
+	for _, b := range []*breaker.Breaker{svc.CodeListBreaker, svc.DatasetBreaker, svc.RendererBreaker} {
+		if err = svc.HealthCheck.AddCheck(b.Name()+" circuit breaker", b.Checker); err != nil {
+			hasErrors = true
+			log.Error(ctx, "failed to add circuit breaker checker", err, log.Data{"dependency": b.Name()})
+		}
+	}
+
 	if hasErrors {
 		return errors.New("Error(s) registering checkers for healthcheck")
 	}
@@ -11,12 +11,14 @@ import (
 
 	"github.com/ONSdigital/dp-api-clients-go/health"
 	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/events"
 	"github.com/ONSdigital/dp-frontend-geography-controller/service"
 	"github.com/ONSdigital/dp-frontend-geography-controller/service/mock"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 	dphttp "github.com/ONSdigital/dp-net/http"
 	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 var (
@@ -74,11 +76,12 @@ func TestRun(t *testing.T) {
 			return failingServerMock
 		}
 
+		routerHTTPClient := newMockHTTPClient(&http.Response{}, nil)
 		funcDoGetHealthClientOk := func(name string, url string) *health.Client {
 			return &health.Client{
 				URL:    url,
 				Name:   name,
-				Client: newMockHTTPClient(&http.Response{}, nil),
+				Client: routerHTTPClient,
 			}
 		}
 
@@ -119,9 +122,14 @@ func TestRun(t *testing.T) {
 				So(err, ShouldNotBeNil)
 				So(err.Error(), ShouldResemble, fmt.Sprintf("unable to register checkers: %s", errAddheckFail.Error()))
 				So(svcList.HealthCheck, ShouldBeTrue)
-				So(len(hcMockAddFail.AddCheckCalls()), ShouldEqual, 2)
+				So(len(hcMockAddFail.AddCheckCalls()), ShouldEqual, 7)
 				So(hcMockAddFail.AddCheckCalls()[0].Name, ShouldResemble, "API router")
 				So(hcMockAddFail.AddCheckCalls()[1].Name, ShouldResemble, "frontend renderer")
+				So(hcMockAddFail.AddCheckCalls()[2].Name, ShouldResemble, "geography cache")
+				So(hcMockAddFail.AddCheckCalls()[3].Name, ShouldResemble, "cache warmer")
+				So(hcMockAddFail.AddCheckCalls()[4].Name, ShouldResemble, "code list API circuit breaker")
+				So(hcMockAddFail.AddCheckCalls()[5].Name, ShouldResemble, "dataset API circuit breaker")
+				So(hcMockAddFail.AddCheckCalls()[6].Name, ShouldResemble, "frontend renderer circuit breaker")
 			})
 		})
 
@@ -134,17 +142,26 @@ func TestRun(t *testing.T) {
 			svcErrors := make(chan error, 1)
 			svcList := service.NewServiceList(initMock)
 			serverWg.Add(1)
-			_, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+			svc, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+			Reset(func() { svc.Warmer.Stop() })
 
 			Convey("Then service Run succeeds and all the flags are set", func() {
 				So(err, ShouldBeNil)
 				So(svcList.HealthCheck, ShouldBeTrue)
+				// the retry policy is the only layer retrying calls to the API router
+				So(routerHTTPClient.SetMaxRetriesCalls(), ShouldHaveLength, 1)
+				So(routerHTTPClient.SetMaxRetriesCalls()[0].N, ShouldEqual, 0)
 			})
 
 			Convey("The checkers are registered and the healthcheck and http server started", func() {
-				So(len(hcMock.AddCheckCalls()), ShouldEqual, 2)
+				So(len(hcMock.AddCheckCalls()), ShouldEqual, 7)
 				So(hcMock.AddCheckCalls()[0].Name, ShouldResemble, "API router")
 				So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "frontend renderer")
+				So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "geography cache")
+				So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "cache warmer")
+				So(hcMock.AddCheckCalls()[4].Name, ShouldResemble, "code list API circuit breaker")
+				So(hcMock.AddCheckCalls()[5].Name, ShouldResemble, "dataset API circuit breaker")
+				So(hcMock.AddCheckCalls()[6].Name, ShouldResemble, "frontend renderer circuit breaker")
 				So(len(initMock.DoGetHTTPServerCalls()), ShouldEqual, 1)
 				So(initMock.DoGetHTTPServerCalls()[0].BindAddr, ShouldEqual, ":23700")
 				So(len(hcMock.StartCalls()), ShouldEqual, 1)
@@ -153,6 +170,114 @@ func TestRun(t *testing.T) {
 			})
 		})
 
+		Convey("Given that all dependencies are successfully initialised with the cache warmer disabled", func() {
+			cfg.CacheWarmerEnabled = false
+			initMock := &mock.InitialiserMock{
+				DoGetHealthClientFunc: funcDoGetHealthClientOk,
+				DoGetHealthCheckFunc:  funcDoGetHealthcheckOk,
+				DoGetHTTPServerFunc:   funcDoGetHTTPServer,
+			}
+			svcErrors := make(chan error, 1)
+			svcList := service.NewServiceList(initMock)
+			serverWg.Add(1)
+			_, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+
+			Convey("Then the cache warmer checker is not registered", func() {
+				So(err, ShouldBeNil)
+				So(len(hcMock.AddCheckCalls()), ShouldEqual, 6)
+				So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "geography cache")
+				So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "code list API circuit breaker")
+				serverWg.Wait() // Wait for HTTP server go-routine to finish
+			})
+		})
+
+		Convey("Given that all dependencies are successfully initialised with an admin bind address", func() {
+			cfg.AdminBindAddr = ":23701"
+			adminServerMock := &mock.HTTPServerMock{
+				ListenAndServeFunc: func() error {
+					serverWg.Done()
+					return nil
+				},
+			}
+			initMock := &mock.InitialiserMock{
+				DoGetHealthClientFunc: funcDoGetHealthClientOk,
+				DoGetHealthCheckFunc:  funcDoGetHealthcheckOk,
+				DoGetHTTPServerFunc: func(bindAddr string, router http.Handler) service.HTTPServer {
+					if bindAddr == cfg.AdminBindAddr {
+						return adminServerMock
+					}
+					return serverMock
+				},
+			}
+			svcErrors := make(chan error, 1)
+			svcList := service.NewServiceList(initMock)
+			serverWg.Add(2)
+			svc, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+			Reset(func() { svc.Warmer.Stop() })
+
+			Convey("Then the admin http server is started on its own address alongside the http server", func() {
+				So(err, ShouldBeNil)
+				So(svc.AdminServer, ShouldEqual, adminServerMock)
+				So(len(initMock.DoGetHTTPServerCalls()), ShouldEqual, 2)
+				So(initMock.DoGetHTTPServerCalls()[0].BindAddr, ShouldEqual, ":23700")
+				So(initMock.DoGetHTTPServerCalls()[1].BindAddr, ShouldEqual, ":23701")
+				serverWg.Wait() // Wait for both HTTP server go-routines to finish
+				So(len(serverMock.ListenAndServeCalls()), ShouldEqual, 1)
+				So(len(adminServerMock.ListenAndServeCalls()), ShouldEqual, 1)
+			})
+		})
+
+		Convey("Given that all dependencies are successfully initialised with change events enabled", func() {
+			cfg.KafkaEnabled = true
+			consumer := events.NewMemory(1)
+			initMock := &mock.InitialiserMock{
+				DoGetHealthClientFunc: funcDoGetHealthClientOk,
+				DoGetHealthCheckFunc:  funcDoGetHealthcheckOk,
+				DoGetHTTPServerFunc:   funcDoGetHTTPServer,
+				DoGetEventConsumerFunc: func(cfg *config.Config) (events.Consumer, error) {
+					return consumer, nil
+				},
+			}
+			svcErrors := make(chan error, 1)
+			svcList := service.NewServiceList(initMock)
+			serverWg.Add(1)
+			svc, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+			Reset(func() { svc.Warmer.Stop() })
+
+			Convey("Then the change event consumer is started and its checker registered", func() {
+				So(err, ShouldBeNil)
+				So(svcList.EventConsumer, ShouldBeTrue)
+				So(svc.EventConsumer, ShouldEqual, consumer)
+				So(len(initMock.DoGetEventConsumerCalls()), ShouldEqual, 1)
+				So(len(hcMock.AddCheckCalls()), ShouldEqual, 8)
+				So(hcMock.AddCheckCalls()[4].Name, ShouldResemble, "change event consumer")
+
+				event := events.Event{Type: events.CodeListPublished, CodeListID: "local-authority"}
+				So(consumer.Publish(ctx, event), ShouldBeNil)
+				So(<-consumer.Handled(), ShouldResemble, event)
+				serverWg.Wait() // Wait for HTTP server go-routine to finish
+			})
+		})
+
+		Convey("Given that the change event consumer cannot be created", func() {
+			cfg.KafkaEnabled = true
+			errConsumer := errors.New("consumer error")
+			initMock := &mock.InitialiserMock{
+				DoGetHealthClientFunc: funcDoGetHealthClientOk,
+				DoGetEventConsumerFunc: func(cfg *config.Config) (events.Consumer, error) {
+					return nil, errConsumer
+				},
+			}
+			svcErrors := make(chan error, 1)
+			svcList := service.NewServiceList(initMock)
+			_, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+
+			Convey("Then service Run fails with the same error and the flag is not set", func() {
+				So(err, ShouldResemble, errConsumer)
+				So(svcList.EventConsumer, ShouldBeFalse)
+			})
+		})
+
 		Convey("Given that all dependencies are successfully initialised but the http server fails", func() {
 
 			initMock := &mock.InitialiserMock{
@@ -163,8 +288,9 @@ func TestRun(t *testing.T) {
 			svcErrors := make(chan error, 1)
 			svcList := service.NewServiceList(initMock)
 			serverWg.Add(1)
-			_, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
+			svc, err := service.Run(ctx, cfg, svcList, testBuildTime, testGitCommit, testVersion, svcErrors)
 			So(err, ShouldBeNil)
+			Reset(func() { svc.Warmer.Stop() })
 
 			Convey("Then the error is sent to the error channel", func() {
 				sErr := <-svcErrors
@@ -217,6 +343,73 @@ func TestClose(t *testing.T) {
 			So(len(serverMock.ShutdownCalls()), ShouldEqual, 1)
 		})
 
+		Convey("Closing the service with an admin server shuts it down after the http server", func() {
+			serverStopped := false
+			serverMock.ShutdownFunc = func(ctx context.Context) error {
+				serverStopped = true
+				return nil
+			}
+			adminServerMock := &mock.HTTPServerMock{
+				ShutdownFunc: func(ctx context.Context) error {
+					if !serverStopped {
+						return errors.New("Admin server stopped before http server")
+					}
+					return nil
+				},
+			}
+			svcList := service.NewServiceList(nil)
+			svcList.HealthCheck = true
+			svc := service.Service{
+				Config:      cfg,
+				ServiceList: svcList,
+				Server:      serverMock,
+				AdminServer: adminServerMock,
+				HealthCheck: hcMock,
+			}
+			err = svc.Close(context.Background())
+			So(err, ShouldBeNil)
+			So(len(serverMock.ShutdownCalls()), ShouldEqual, 1)
+			So(len(adminServerMock.ShutdownCalls()), ShouldEqual, 1)
+		})
+
+		Convey("Closing the service stops the change event consumer", func() {
+			consumer := events.NewMemory(1)
+			consumer.Start(ctx, func(ctx context.Context, event events.Event) error { return nil })
+			svcList := service.NewServiceList(nil)
+			svcList.HealthCheck = true
+			svcList.EventConsumer = true
+			svc := service.Service{
+				Config:        cfg,
+				ServiceList:   svcList,
+				Server:        serverMock,
+				HealthCheck:   hcMock,
+				EventConsumer: consumer,
+			}
+			err = svc.Close(context.Background())
+			So(err, ShouldBeNil)
+			So(consumer.Publish(ctx, events.Event{Type: events.CodeListPublished, CodeListID: "local-authority"}), ShouldEqual, events.ErrStopped)
+		})
+
+		Convey("Closing the service shuts the tracer provider down, exporting the spans not yet exported", func() {
+			exporter := &recordingExporter{}
+			provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(time.Hour)))
+			_, span := provider.Tracer("test").Start(ctx, "GET /geography")
+			span.End()
+			svcList := service.NewServiceList(nil)
+			svcList.HealthCheck = true
+			svc := service.Service{
+				Config:         cfg,
+				ServiceList:    svcList,
+				Server:         serverMock,
+				HealthCheck:    hcMock,
+				TracerProvider: provider,
+			}
+			So(exporter.spans, ShouldBeEmpty)
+			err = svc.Close(context.Background())
+			So(err, ShouldBeNil)
+			So(exporter.spans, ShouldHaveLength, 1)
+		})
+
 		Convey("If services fail to stop, the Close operation tries to close all dependencies and returns an error", func() {
 
 			failingserverMock := &mock.HTTPServerMock{
@@ -246,7 +439,7 @@ func TestClose(t *testing.T) {
 			timeoutServerMock := &mock.HTTPServerMock{
 				ListenAndServeFunc: func() error { return nil },
 				ShutdownFunc: func(ctx context.Context) error {
-					time.Sleep(2 * time.Millisecond)
+					<-ctx.Done()
 					return nil
 				},
 			}
@@ -293,10 +486,23 @@ func TestGetAPIRouterVersion(t *testing.T) {
 	})
 }
 
+// recordingExporter is a span exporter that keeps the spans exported to it, even once it is shut down
+type recordingExporter struct {
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *recordingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error { return nil }
+
 func newMockHTTPClient(r *http.Response, err error) *dphttp.ClienterMock {
 	return &dphttp.ClienterMock{
 		SetPathsWithNoRetriesFunc: func(paths []string) {},
 		GetPathsWithNoRetriesFunc: func() []string { return []string{} },
+		SetMaxRetriesFunc:         func(maxRetries int) {},
 		DoFunc: func(ctx context.Context, req *http.Request) (*http.Response, error) {
 			return r, err
 		},
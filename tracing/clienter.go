@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Clienter is a dphttp.Clienter that makes each request through the client it wraps in a client span, and passes the
+// span on to the server in the request's traceparent header
+type Clienter struct {
+	dphttp.Clienter
+}
+
+// NewClienter wraps the provided client so that its requests are traced
+func NewClienter(client dphttp.Clienter) *Clienter {
+	return &Clienter{Clienter: client}
+}
+
+// Do makes the request in a client span
+func (c *Clienter) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.traced(ctx, req, func(req *http.Request) (*http.Response, error) {
+		return c.Clienter.Do(ctx, req)
+	})
+}
+
+// RoundTrip makes the request in a client span, as a child of the span in the request's context
+func (c *Clienter) RoundTrip(req *http.Request) (*http.Response, error) {
+	return c.traced(req.Context(), req, c.Clienter.RoundTrip)
+}
+
+// Get calls Do with a GET
+func (c *Clienter) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req)
+}
+
+// Head calls Do with a HEAD
+func (c *Clienter) Head(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequest("HEAD", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, req)
+}
+
+// Post calls Do with a POST and the provided content type and body
+func (c *Clienter) Post(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.Do(ctx, req)
+}
+
+// Put calls Do with a PUT and the provided content type and body
+func (c *Clienter) Put(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest("PUT", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.Do(ctx, req)
+}
+
+// PostForm calls Post with the form content type
+func (c *Clienter) PostForm(ctx context.Context, uri string, data url.Values) (*http.Response, error) {
+	return c.Post(ctx, uri, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+}
+
+// traced starts a client span for req as a child of the span in ctx, and makes a copy of req carrying the span in
+// its traceparent header with do
+func (c *Clienter) traced(ctx context.Context, req *http.Request, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx, span := tracer().Start(ctx, "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(req)...),
+	)
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := do(req)
+	if err != nil {
+		handlers.EndSpan(span, err)
+		return resp, err
+	}
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(resp.StatusCode, trace.SpanKindClient))
+	span.End()
+	return resp, nil
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dphttp "github.com/ONSdigital/dp-net/http"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestClienter(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a traced client and a server that records the traceparent of each request", t, func() {
+		recorder := recordSpans()
+		var traceparents, bodies []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			traceparents = append(traceparents, req.Header.Get("traceparent"))
+			body, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			if req.URL.Path == "/broken" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer server.Close()
+
+		client := NewClienter(dphttp.NewClient())
+		client.SetMaxRetries(0)
+		spanCtx, parent := otel.Tracer("test").Start(ctx, "GET /geography")
+
+		Convey("its settings are those of the client it wraps", func() {
+			So(client.GetMaxRetries(), ShouldEqual, 0)
+		})
+
+		Convey("a request is made in a client span that is passed on in its traceparent header", func() {
+			resp, err := client.Get(spanCtx, server.URL+"/code-lists")
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			parent.End()
+
+			spans := spansNamed(recorder.Ended(), "HTTP GET")
+			So(spans, ShouldHaveLength, 1)
+			So(spans[0].SpanKind(), ShouldEqual, trace.SpanKindClient)
+			So(spans[0].Parent().SpanID(), ShouldEqual, parent.SpanContext().SpanID())
+			So(traceparents, ShouldResemble, []string{"00-" + parent.SpanContext().TraceID().String() + "-" + spans[0].SpanContext().SpanID().String() + "-01"})
+		})
+
+		Convey("a request with a body keeps its body", func() {
+			resp, err := client.Post(spanCtx, server.URL+"/code-lists", "text/plain", strings.NewReader("body"))
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+
+			So(bodies, ShouldResemble, []string{"body"})
+			So(traceparents[0], ShouldStartWith, "00-"+parent.SpanContext().TraceID().String())
+		})
+
+		Convey("a request made with RoundTrip is traced as a child of the span in its context", func() {
+			req, err := http.NewRequestWithContext(spanCtx, "GET", server.URL+"/code-lists", nil)
+			So(err, ShouldBeNil)
+			resp, err := client.RoundTrip(req)
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+
+			spans := spansNamed(recorder.Ended(), "HTTP GET")
+			So(spans, ShouldHaveLength, 1)
+			So(spans[0].Parent().SpanID(), ShouldEqual, parent.SpanContext().SpanID())
+			So(req.Header.Get("traceparent"), ShouldBeEmpty)
+			So(traceparents[0], ShouldNotBeEmpty)
+		})
+
+		Convey("a server error marks the client span as failed", func() {
+			resp, err := client.Get(spanCtx, server.URL+"/broken")
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+
+			spans := spansNamed(recorder.Ended(), "HTTP GET")
+			So(spans, ShouldHaveLength, 1)
+			So(spans[0].Status().Code, ShouldEqual, codes.Error)
+		})
+	})
+}
+
+func TestPropagationWithoutTracing(t *testing.T) {
+
+	Convey("Given tracing is disabled", t, func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		var traceparents []string
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			traceparents = append(traceparents, req.Header.Get("traceparent"))
+		}))
+		defer api.Close()
+
+		client := NewClienter(dphttp.NewClient())
+		router := mux.NewRouter()
+		router.Use(Middleware)
+		router.Path("/geography").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			resp, err := client.Get(req.Context(), api.URL+"/code-lists")
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+		})
+
+		Convey("the traceparent of an incoming request is passed on unchanged", func() {
+			req := httptest.NewRequest("GET", "/geography", nil)
+			req.Header.Set("traceparent", testTraceparent)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+			So(traceparents, ShouldResemble, []string{testTraceparent})
+		})
+	})
+}
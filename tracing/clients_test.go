@@ -0,0 +1,137 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-api-clients-go/dataset"
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// attributeValue returns the value of the span's attribute with the provided key, as a string
+func attributeValue(span sdktrace.ReadOnlySpan, key string) string {
+	for _, attr := range span.Attributes() {
+		if attr.Key == attribute.Key(key) {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestHandlerSpans(t *testing.T) {
+
+	Convey("Given traced handlers and clients", t, func() {
+		recorder := recordSpans()
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+
+		codeListClient := &handlers.CodeListClientMock{
+			GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+				var results codelist.CodeListResults
+				for _, id := range []string{"countries", "local-authority", "regions"} {
+					results.Items = append(results.Items, codelist.CodeList{Links: codelist.CodeListLinks{Self: &codelist.Link{ID: id}}})
+				}
+				return results, nil
+			},
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				return codelist.EditionsListResults{Items: []codelist.EditionsList{{Label: codeListID, Edition: "2019"}}}, nil
+			},
+			GetCodeByIDFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+				return codelist.CodeResult{Label: "Hartlepool"}, nil
+			},
+			GetDatasetsByCodeFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+				var datasets codelist.DatasetsResult
+				for _, id := range []string{"cpih01", "mid-year-pop-est"} {
+					datasets.Datasets = append(datasets.Datasets, codelist.Dataset{
+						Links: codelist.DatasetLinks{Self: codelist.Link{ID: id}},
+						Editions: []codelist.DatasetEdition{{
+							Links: codelist.DatasetEditionLink{
+								Self:          codelist.Link{ID: "time-series"},
+								LatestVersion: codelist.Link{ID: "1", Href: "http://localhost:22000/datasets/" + id + "/editions/time-series/versions/1"},
+							},
+						}},
+					})
+				}
+				return datasets, nil
+			},
+		}
+		datasetClient := &handlers.DatasetClientMock{
+			GetFunc: func(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID string) (dataset.DatasetDetails, error) {
+				return dataset.DatasetDetails{Title: datasetID}, nil
+			},
+		}
+		rendererClient := &handlers.RenderClientMock{
+			DoFunc: func(string, []byte) ([]byte, error) {
+				return []byte("<html></html>"), nil
+			},
+		}
+
+		router := mux.NewRouter()
+		router.Use(Middleware)
+		router.Path("/geography").HandlerFunc(handlers.HomepageRender(*cfg, rendererClient, NewCodeListClient(codeListClient)))
+		router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(handlers.AreaPageRender(*cfg, rendererClient, NewCodeListClient(codeListClient), NewDatasetClient(datasetClient), ""))
+		serve := func(target string) int {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+			return w.Code
+		}
+
+		Convey("the homepage's calls for the editions of each code list are children of its fan-out span", func() {
+			So(serve("/geography"), ShouldEqual, http.StatusOK)
+			spans := recorder.Ended()
+
+			server := spansNamed(spans, "GET /geography")
+			So(server, ShouldHaveLength, 1)
+			serverID := server[0].SpanContext().SpanID()
+			So(spansNamed(spans, "codelist.GetGeographyCodeLists")[0].Parent().SpanID(), ShouldEqual, serverID)
+			So(spansNamed(spans, "render")[0].Parent().SpanID(), ShouldEqual, serverID)
+
+			fanOut := spansNamed(spans, "fan-out")
+			So(fanOut, ShouldHaveLength, 1)
+			So(fanOut[0].Parent().SpanID(), ShouldEqual, serverID)
+			So(attributeValue(fanOut[0], "handler"), ShouldEqual, "homepage")
+			So(attributeValue(fanOut[0], "size"), ShouldEqual, "3")
+
+			editions := spansNamed(spans, "codelist.GetCodeListEditions")
+			So(editions, ShouldHaveLength, 3)
+			var codeListIDs []string
+			for _, span := range editions {
+				So(span.Parent().SpanID(), ShouldEqual, fanOut[0].SpanContext().SpanID())
+				codeListIDs = append(codeListIDs, attributeValue(span, "code_list_id"))
+			}
+			So(codeListIDs, ShouldContain, "countries")
+			So(codeListIDs, ShouldContain, "local-authority")
+			So(codeListIDs, ShouldContain, "regions")
+		})
+
+		Convey("the area page's calls for each dataset are children of its fan-out span", func() {
+			So(serve("/geography/local-authority/E06000001"), ShouldEqual, http.StatusOK)
+			spans := recorder.Ended()
+
+			server := spansNamed(spans, "GET /geography/{codeListID}/{codeID}")
+			So(server, ShouldHaveLength, 1)
+			So(attributeValue(server[0], "code_list_id"), ShouldEqual, "local-authority")
+			So(attributeValue(server[0], "code_id"), ShouldEqual, "E06000001")
+			So(attributeValue(server[0], "edition"), ShouldEqual, "2019")
+
+			fanOut := spansNamed(spans, "fan-out")
+			So(fanOut, ShouldHaveLength, 1)
+			So(fanOut[0].Parent().SpanID(), ShouldEqual, server[0].SpanContext().SpanID())
+			So(attributeValue(fanOut[0], "handler"), ShouldEqual, "area_page")
+
+			datasets := spansNamed(spans, "dataset.Get")
+			So(datasets, ShouldHaveLength, 2)
+			for _, span := range datasets {
+				So(span.Parent().SpanID(), ShouldEqual, fanOut[0].SpanContext().SpanID())
+			}
+		})
+	})
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CodeListClient is a handlers.CodeListClient that makes each call to the client it wraps in a span, so that calls
+// answered from the cache are traced as well as those that reach the code list API
+type CodeListClient struct {
+	client handlers.CodeListClient
+}
+
+// NewCodeListClient wraps the provided client so that its calls are traced
+func NewCodeListClient(client handlers.CodeListClient) *CodeListClient {
+	return &CodeListClient{client: client}
+}
+
+// GetGeographyCodeLists returns the geography code lists
+func (c *CodeListClient) GetGeographyCodeLists(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+	ctx, span := tracer().Start(ctx, "codelist.GetGeographyCodeLists")
+	results, err := c.client.GetGeographyCodeLists(ctx, userAuthToken, serviceAuthToken)
+	handlers.EndSpan(span, err)
+	return results, err
+}
+
+// GetCodeListEditions returns the editions of a code list
+func (c *CodeListClient) GetCodeListEditions(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+	ctx, span := tracer().Start(ctx, "codelist.GetCodeListEditions", trace.WithAttributes(stringAttributes("code_list_id", codeListID)...))
+	editions, err := c.client.GetCodeListEditions(ctx, userAuthToken, serviceAuthToken, codeListID)
+	handlers.EndSpan(span, err)
+	return editions, err
+}
+
+// GetCodes returns the codes of an edition of a code list
+func (c *CodeListClient) GetCodes(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+	ctx, span := tracer().Start(ctx, "codelist.GetCodes", trace.WithAttributes(stringAttributes("code_list_id", codeListID, "edition", edition)...))
+	codes, err := c.client.GetCodes(ctx, userAuthToken, serviceAuthToken, codeListID, edition)
+	handlers.EndSpan(span, err)
+	return codes, err
+}
+
+// GetCodeByID returns a code of an edition of a code list
+func (c *CodeListClient) GetCodeByID(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.CodeResult, error) {
+	ctx, span := tracer().Start(ctx, "codelist.GetCodeByID", trace.WithAttributes(stringAttributes("code_list_id", codeListID, "edition", edition, "code_id", codeID)...))
+	code, err := c.client.GetCodeByID(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+	handlers.EndSpan(span, err)
+	return code, err
+}
+
+// GetDatasetsByCode returns the datasets related to a code
+func (c *CodeListClient) GetDatasetsByCode(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string, codeID string) (codelist.DatasetsResult, error) {
+	ctx, span := tracer().Start(ctx, "codelist.GetDatasetsByCode", trace.WithAttributes(stringAttributes("code_list_id", codeListID, "edition", edition, "code_id", codeID)...))
+	datasets, err := c.client.GetDatasetsByCode(ctx, userAuthToken, serviceAuthToken, codeListID, edition, codeID)
+	handlers.EndSpan(span, err)
+	return datasets, err
+}
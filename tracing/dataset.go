@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-api-clients-go/dataset"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DatasetClient is a handlers.DatasetClient that makes each call to the client it wraps in a span
+type DatasetClient struct {
+	client handlers.DatasetClient
+}
+
+// NewDatasetClient wraps the provided client so that its calls are traced
+func NewDatasetClient(client handlers.DatasetClient) *DatasetClient {
+	return &DatasetClient{client: client}
+}
+
+// Get returns the details of a dataset
+func (c *DatasetClient) Get(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID string) (dataset.DatasetDetails, error) {
+	ctx, span := tracer().Start(ctx, "dataset.Get", trace.WithAttributes(stringAttributes("dataset_id", datasetID)...))
+	details, err := c.client.Get(ctx, userAuthToken, serviceAuthToken, collectionID, datasetID)
+	handlers.EndSpan(span, err)
+	return details, err
+}
+
+// GetVersion returns a version of an edition of a dataset
+func (c *DatasetClient) GetVersion(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (dataset.Version, error) {
+	ctx, span := tracer().Start(ctx, "dataset.GetVersion", trace.WithAttributes(stringAttributes("dataset_id", datasetID, "edition", edition, "version", version)...))
+	v, err := c.client.GetVersion(ctx, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version)
+	handlers.EndSpan(span, err)
+	return v, err
+}
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans started by this package
+const instrumentationName = "github.com/ONSdigital/dp-frontend-geography-controller/tracing"
+
+// Init sets the global propagator to W3C trace context, so that the traceparent of an incoming request is passed on
+// to the requests made to handle it whether or not tracing is enabled. If tracing is enabled the global tracer
+// provider is set to one created by NewProvider, which is returned so that it can be shut down, exporting any spans
+// not yet exported. The provider is nil if tracing is disabled.
+func Init(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if !cfg.OTelEnabled {
+		return nil, nil
+	}
+
+	provider, err := NewProvider(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+	return provider, nil
+}
+
+// NewProvider creates a tracer provider that exports spans in batches to the configured OTLP endpoint over HTTP.
+// Traces are started for the configured ratio of requests that are not already part of a trace; requests that are
+// follow the sampling decision of their parent.
+func NewProvider(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTelExporterOTLPEndpoint)}
+	if cfg.OTelExporterOTLPInsecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(cfg.OTelBatchTimeout)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.OTelSampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(cfg.OTelServiceName))),
+	), nil
+}
+
+// tracer returns the tracer of the global provider, so that spans go to whichever provider is current
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Middleware is a mux middleware that handles each request in a server span named after the template of the route
+// it matched. The span continues the trace in the request's traceparent header, if it has one.
+func Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route := handlers.RouteTemplate(req)
+
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := tracer().Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", route, req)...),
+		)
+		defer span.End()
+
+		sw := handlers.NewStatusWriter(w)
+		h.ServeHTTP(sw, req.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(sw.Status())...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(sw.Status(), trace.SpanKindServer))
+	})
+}
+
+// stringAttributes returns string attributes from pairs of keys and values, leaving out those without a value
+func stringAttributes(kv ...string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			attrs = append(attrs, attribute.String(kv[i], kv[i+1]))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,249 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/config"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentID    = "00f067aa0ba902b7"
+	testTraceparent = "00-" + testTraceID + "-" + testParentID + "-01"
+)
+
+// recordSpans sets the global tracer provider to one that records every span, and returns the recorder
+func recordSpans() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+// spansNamed returns the spans with the provided name
+func spansNamed(spans []sdktrace.ReadOnlySpan, name string) []sdktrace.ReadOnlySpan {
+	var named []sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if span.Name() == name {
+			named = append(named, span)
+		}
+	}
+	return named
+}
+
+// standInCollector is a local stand-in for an OTLP collector, which receives the spans exported to it over HTTP
+type standInCollector struct {
+	*httptest.Server
+	exports chan *coltracepb.ExportTraceServiceRequest
+}
+
+func newStandInCollector() *standInCollector {
+	c := &standInCollector{exports: make(chan *coltracepb.ExportTraceServiceRequest, 10)}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" || req.URL.Path != "/v1/traces" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var export coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &export); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.exports <- &export
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	return c
+}
+
+// spans returns the spans exported so far, by name, and the service names of the resources they were exported for
+func (c *standInCollector) spans() (map[string]*tracepb.Span, []string) {
+	spans := map[string]*tracepb.Span{}
+	var services []string
+	for {
+		select {
+		case export := <-c.exports:
+			for _, rs := range export.ResourceSpans {
+				for _, attr := range rs.Resource.Attributes {
+					if attr.Key == "service.name" {
+						services = append(services, attr.Value.GetStringValue())
+					}
+				}
+				for _, ss := range rs.ScopeSpans {
+					for _, span := range ss.Spans {
+						spans[span.Name] = span
+					}
+				}
+			}
+		default:
+			return spans, services
+		}
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a stand-in OTLP collector and a provider configured to export to it", t, func() {
+		collector := newStandInCollector()
+		defer collector.Close()
+
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		cfg.OTelExporterOTLPEndpoint = strings.TrimPrefix(collector.URL, "http://")
+		cfg.OTelBatchTimeout = time.Hour
+
+		Convey("spans are exported with the service name when the provider is shut down", func() {
+			provider, err := NewProvider(ctx, cfg)
+			So(err, ShouldBeNil)
+
+			spanCtx, parent := provider.Tracer("test").Start(ctx, "GET /geography")
+			_, child := provider.Tracer("test").Start(spanCtx, "fan-out")
+			child.End()
+			parent.End()
+			So(provider.Shutdown(ctx), ShouldBeNil)
+
+			spans, services := collector.spans()
+			So(services, ShouldContain, "dp-frontend-geography-controller")
+			So(spans, ShouldHaveLength, 2)
+			So(spans["fan-out"].ParentSpanId, ShouldResemble, spans["GET /geography"].SpanId)
+		})
+
+		Convey("no new traces are sampled with a ratio of 0, but traces sampled upstream are still followed", func() {
+			cfg.OTelSampleRatio = 0
+			provider, err := NewProvider(ctx, cfg)
+			So(err, ShouldBeNil)
+
+			_, unsampled := provider.Tracer("test").Start(ctx, "GET /geography")
+			unsampled.End()
+
+			header := http.Header{"Traceparent": []string{testTraceparent}}
+			remoteCtx := propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(header))
+			_, sampled := provider.Tracer("test").Start(remoteCtx, "GET /geography/{codeListID}")
+			sampled.End()
+			So(provider.Shutdown(ctx), ShouldBeNil)
+
+			spans, _ := collector.spans()
+			So(spans, ShouldHaveLength, 1)
+			So(spans, ShouldContainKey, "GET /geography/{codeListID}")
+		})
+	})
+}
+
+func TestInit(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given tracing is disabled", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+		Convey("no provider is created, but traceparent headers are still propagated", func() {
+			provider, err := Init(ctx, cfg)
+			So(err, ShouldBeNil)
+			So(provider, ShouldBeNil)
+			So(otel.GetTextMapPropagator().Fields(), ShouldContain, "traceparent")
+		})
+	})
+	Convey("Given tracing is enabled", t, func() {
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		cfg.OTelEnabled = true
+
+		Convey("the provider created is set as the global provider", func() {
+			provider, err := Init(ctx, cfg)
+			So(err, ShouldBeNil)
+			So(provider, ShouldNotBeNil)
+			So(otel.GetTracerProvider(), ShouldEqual, provider)
+			So(provider.Shutdown(ctx), ShouldBeNil)
+		})
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+
+	Convey("Given a router whose routes are traced", t, func() {
+		recorder := recordSpans()
+		router := mux.NewRouter()
+		router.Use(Middleware)
+		router.Path("/geography/{codeListID}/{codeID}").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch mux.Vars(req)["codeID"] {
+			case "missing":
+				w.WriteHeader(http.StatusNotFound)
+			case "broken":
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			w.Write([]byte("<html></html>"))
+		})
+		serve := func(target string, header http.Header) {
+			req := httptest.NewRequest("GET", target, nil)
+			for k, v := range header {
+				req.Header[k] = v
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		Convey("a request is handled in a server span named after its route template", func() {
+			serve("/geography/local-authority/E06000001", nil)
+
+			spans := recorder.Ended()
+			So(spans, ShouldHaveLength, 1)
+			So(spans[0].Name(), ShouldEqual, "GET /geography/{codeListID}/{codeID}")
+			So(spans[0].SpanKind(), ShouldEqual, trace.SpanKindServer)
+			So(spans[0].Parent().IsValid(), ShouldBeFalse)
+			So(spans[0].Status().Code, ShouldEqual, codes.Unset)
+		})
+
+		Convey("a request with a traceparent header continues its trace", func() {
+			serve("/geography/local-authority/E06000001", http.Header{"Traceparent": []string{testTraceparent}})
+
+			spans := recorder.Ended()
+			So(spans, ShouldHaveLength, 1)
+			So(spans[0].SpanContext().TraceID().String(), ShouldEqual, testTraceID)
+			So(spans[0].Parent().SpanID().String(), ShouldEqual, testParentID)
+			So(spans[0].Parent().IsRemote(), ShouldBeTrue)
+		})
+
+		Convey("only server errors mark the span as failed", func() {
+			serve("/geography/local-authority/missing", nil)
+			serve("/geography/local-authority/broken", nil)
+
+			spans := recorder.Ended()
+			So(spans, ShouldHaveLength, 2)
+			So(spans[0].Status().Code, ShouldEqual, codes.Unset)
+			So(spans[1].Status().Code, ShouldEqual, codes.Error)
+		})
+
+		Convey("a request that matches no route is traced with the same route as in the metrics", func() {
+			router.NotFoundHandler = Middleware(http.NotFoundHandler())
+			serve("/unknown", nil)
+
+			spans := recorder.Ended()
+			So(spans, ShouldHaveLength, 1)
+			So(spans[0].Name(), ShouldEqual, "GET "+handlers.UnmatchedRoute)
+		})
+	})
+}
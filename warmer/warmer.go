@@ -0,0 +1,143 @@
+package warmer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/log.go/v2/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// Warmer populates the code list cache with the data behind the homepage and the default edition of each list page,
+// so that the first users after a deploy, or after the cache expires, do not wait for every downstream call.
+type Warmer struct {
+	client        handlers.CodeListClient
+	interval      time.Duration
+	concurrency   int
+	holdReadiness bool
+
+	mutex  sync.Mutex
+	warmed bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a Warmer that makes its requests through client, which should be the cached code list client. The
+// cache is warmed again every interval, unless interval is zero or less, with at most concurrency code lists warmed
+// at once. If holdReadiness is set, the warmer's health check reports a warning until the first warm has completed.
+func New(client handlers.CodeListClient, interval time.Duration, concurrency int, holdReadiness bool) *Warmer {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Warmer{
+		client:        client,
+		interval:      interval,
+		concurrency:   concurrency,
+		holdReadiness: holdReadiness,
+	}
+}
+
+// Start warms the cache in the background straight away, then on the configured interval until Stop is called
+func (w *Warmer) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		w.run(ctx)
+		if w.interval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.run(ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels any warm in progress and waits for the warmer to finish
+func (w *Warmer) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	<-w.done
+}
+
+// Checker reports a warning until the first warm has completed, if readiness is being held back for it
+func (w *Warmer) Checker(ctx context.Context, state *health.CheckState) error {
+	w.mutex.Lock()
+	warmed := w.warmed
+	w.mutex.Unlock()
+
+	if w.holdReadiness && !warmed {
+		return state.Update(health.StatusWarning, "waiting for the first cache warm to complete", 0)
+	}
+	return state.Update(health.StatusOK, "cache warmer is running", 0)
+}
+
+func (w *Warmer) run(ctx context.Context) {
+	start := time.Now()
+	codeLists, failed, err := w.Warm(ctx)
+	logData := log.Data{"code_lists": codeLists, "failed": failed, "duration": time.Since(start).String()}
+	if err != nil {
+		log.Warn(ctx, "cache warm failed", logData, log.FormatErrors([]error{err}))
+	} else {
+		log.Info(ctx, "cache warm complete", logData)
+	}
+
+	w.mutex.Lock()
+	w.warmed = true
+	w.mutex.Unlock()
+}
+
+// Warm requests the geography code lists, then the editions of each code list and the codes of its default edition,
+// which is the first. It returns the number of code lists and how many of them could not be warmed, or an error if
+// the code lists themselves could not be requested.
+func (w *Warmer) Warm(ctx context.Context) (int, int, error) {
+	codeLists, err := w.client.GetGeographyCodeLists(ctx, "", "")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var failed int32
+	var g errgroup.Group
+	g.SetLimit(w.concurrency)
+	for _, item := range codeLists.Items {
+		if item.Links.Self == nil {
+			continue
+		}
+		codeListID := item.Links.Self.ID
+		g.Go(func() error {
+			if err := w.warmCodeList(ctx, codeListID); err != nil {
+				atomic.AddInt32(&failed, 1)
+				log.Warn(ctx, "error warming cache for code list", log.Data{"code_list_id": codeListID}, log.FormatErrors([]error{err}))
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	return len(codeLists.Items), int(failed), nil
+}
+
+func (w *Warmer) warmCodeList(ctx context.Context, codeListID string) error {
+	editions, err := w.client.GetCodeListEditions(ctx, "", "", codeListID)
+	if err != nil || len(editions.Items) == 0 {
+		return err
+	}
+	_, err = w.client.GetCodes(ctx, "", "", codeListID, editions.Items[0].Edition)
+	return err
+}
@@ -0,0 +1,112 @@
+package warmer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/codelist"
+	"github.com/ONSdigital/dp-frontend-geography-controller/handlers"
+	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWarmer(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a code list API with three geography code lists", t, func() {
+		var mutex sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		mockClient := &handlers.CodeListClientMock{
+			GetGeographyCodeListsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+				return codelist.CodeListResults{Items: []codelist.CodeList{
+					{Links: codelist.CodeListLinks{Self: &codelist.Link{ID: "local-authority"}}},
+					{Links: codelist.CodeListLinks{Self: &codelist.Link{ID: "countries"}}},
+					{Links: codelist.CodeListLinks{Self: &codelist.Link{ID: "regions"}}},
+				}}, nil
+			},
+			GetCodeListEditionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string) (codelist.EditionsListResults, error) {
+				mutex.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mutex.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				mutex.Lock()
+				inFlight--
+				mutex.Unlock()
+
+				if codeListID == "regions" {
+					return codelist.EditionsListResults{}, errors.New("code-list api unavailable")
+				}
+				return codelist.EditionsListResults{Items: []codelist.EditionsList{{Edition: "2019"}, {Edition: "2018"}}}, nil
+			},
+			GetCodesFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, codeListID string, edition string) (codelist.CodesResults, error) {
+				return codelist.CodesResults{}, nil
+			},
+		}
+
+		Convey("a warm requests the codes of the default edition of each code list, with bounded concurrency", func() {
+			w := New(mockClient, 0, 2, false)
+			codeLists, failed, err := w.Warm(ctx)
+			So(err, ShouldBeNil)
+			So(codeLists, ShouldEqual, 3)
+			So(failed, ShouldEqual, 1)
+			So(maxInFlight, ShouldBeLessThanOrEqualTo, 2)
+
+			calls := mockClient.GetCodesCalls()
+			So(calls, ShouldHaveLength, 2)
+			for _, call := range calls {
+				So(call.Edition, ShouldEqual, "2019")
+				So(call.UserAuthToken, ShouldBeEmpty)
+			}
+		})
+
+		Convey("a warm fails if the code lists cannot be requested", func() {
+			mockClient.GetGeographyCodeListsFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+				return codelist.CodeListResults{}, errors.New("code-list api unavailable")
+			}
+			_, _, err := New(mockClient, 0, 2, false).Warm(ctx)
+			So(err, ShouldNotBeNil)
+			So(mockClient.GetCodeListEditionsCalls(), ShouldHaveLength, 0)
+		})
+
+		Convey("a started warmer warms again on its interval until it is stopped", func() {
+			w := New(mockClient, 10*time.Millisecond, 2, false)
+			w.Start(ctx)
+			time.Sleep(35 * time.Millisecond)
+			w.Stop()
+
+			warms := len(mockClient.GetGeographyCodeListsCalls())
+			So(warms, ShouldBeGreaterThanOrEqualTo, 2)
+			time.Sleep(20 * time.Millisecond)
+			So(mockClient.GetGeographyCodeListsCalls(), ShouldHaveLength, warms)
+		})
+
+		Convey("readiness is held back until the first warm has completed, if configured", func() {
+			release := make(chan struct{})
+			getCodeLists := mockClient.GetGeographyCodeListsFunc
+			mockClient.GetGeographyCodeListsFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string) (codelist.CodeListResults, error) {
+				<-release
+				return getCodeLists(ctx, userAuthToken, serviceAuthToken)
+			}
+			checkState := func(w *Warmer) string {
+				state := health.NewCheckState("cache warmer")
+				So(w.Checker(ctx, state), ShouldBeNil)
+				return state.Status()
+			}
+
+			held := New(mockClient, 0, 2, true)
+			held.Start(ctx)
+			So(checkState(held), ShouldEqual, health.StatusWarning)
+			So(checkState(New(mockClient, 0, 2, false)), ShouldEqual, health.StatusOK)
+
+			close(release)
+			held.Stop()
+			So(checkState(held), ShouldEqual, health.StatusOK)
+		})
+	})
+}